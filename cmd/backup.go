@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Shopify/themekit/src/cmdutil"
+	"github.com/Shopify/themekit/src/colors"
+	"github.com/Shopify/themekit/src/file"
+)
+
+// defaultBackupFlag is the value of --backup when it is passed without a directory
+const defaultBackupFlag = "default"
+
+// backupRemote will download every remote file into a new timestamped directory
+// so that there is a restore point before the remote theme is changed.
+func backupRemote(ctx *cmdutil.Ctx, keys []string) (string, error) {
+	dir := ctx.Flags.Backup
+	if dir == defaultBackupFlag {
+		// backups are kept next to the project so that they are never deployed
+		dir = filepath.Clean(ctx.Env.Directory) + "-backups"
+	}
+	name := strings.Replace(ctx.Env.Name, ":", "-", -1)
+	dir = filepath.Join(dir, fmt.Sprintf("%s-%s", name, time.Now().Format("20060102-150405")))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return dir, err
+	}
+
+	actions := map[string]file.Op{}
+	for _, key := range keys {
+		actions[key] = file.Get
+	}
+	backupCtx := ctx.WithDirectory(dir)
+	performAll(backupCtx, actions)
+	if backupCtx.HasErrors() {
+		return dir, fmt.Errorf("could not back up every file to %s", dir)
+	}
+
+	ctx.Log.Printf("[%s] Backed up %v files to %s", colors.Green(ctx.Env.Name), len(keys), colors.Blue(dir))
+	return dir, nil
+}
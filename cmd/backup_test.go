@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Shopify/themekit/src/env"
+	"github.com/Shopify/themekit/src/shopify"
+)
+
+func TestBackupRemote(t *testing.T) {
+	dir, err := ioutil.TempDir("", "themekit-backup")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx, client, _, stdOut, _ := createTestCtx()
+	ctx.Env.Name = "development:123"
+	ctx.Flags.Backup = dir
+	client.On("GetAsset", mock.Anything, "templates/index.liquid").Return(shopify.Asset{Key: "templates/index.liquid", Value: "index"}, nil)
+	client.On("GetAsset", mock.Anything, "assets/app.js").Return(shopify.Asset{Key: "assets/app.js", Value: "app"}, nil)
+
+	backupDir, err := backupRemote(ctx, []string{"templates/index.liquid", "assets/app.js"})
+	assert.Nil(t, err)
+	assert.Equal(t, dir, filepath.Dir(backupDir))
+	assert.Contains(t, filepath.Base(backupDir), "development-123-")
+	assert.Equal(t, "", ctx.Env.Directory)
+	contents, err := ioutil.ReadFile(filepath.Join(backupDir, "templates", "index.liquid"))
+	assert.Nil(t, err)
+	assert.Equal(t, "index", string(contents))
+	assert.Contains(t, stdOut.String(), "Backed up 2 files")
+
+	ctx, client, _, _, stdErr := createTestCtx()
+	ctx.Env.Directory = filepath.Join(dir, "project")
+	ctx.Flags.Backup = defaultBackupFlag
+	client.On("GetAsset", mock.Anything, "assets/app.js").Return(shopify.Asset{}, fmt.Errorf("server error"))
+	backupDir, err = backupRemote(ctx, []string{"assets/app.js"})
+	assert.Equal(t, filepath.Join(dir, "project-backups"), filepath.Dir(backupDir))
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "could not back up every file")
+	}
+	assert.Contains(t, stdErr.String(), "error downloading assets/app.js: server error")
+	assert.False(t, ctx.HasErrors())
+}
+
+func TestDeployBackupFailure(t *testing.T) {
+	ctx, client, _, _, _ := createTestCtx()
+	ctx.Env.Directory = filepath.Join("_testdata", "projectdir")
+	ctx.Flags.Backup = filepath.Join("_testdata", "notify_file", "nope")
+	client.On("GetAllAssets").Return([]shopify.Asset{{Key: "assets/logo.png"}}, nil)
+	err := deploy(ctx)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "backup failed so nothing was deployed")
+	}
+	client.AssertNotCalled(t, "DeleteAsset", shopify.Asset{Key: "assets/logo.png"})
+}
+
+func TestDeployBackupJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "themekit-backup")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Query().Get("asset[key]") != "":
+			fmt.Fprint(w, `{"asset":{"key":"assets/logo.png","value":"logo"}}`)
+		case r.Method == "GET":
+			fmt.Fprint(w, `{"assets":[{"key":"assets/logo.png","checksum":"abc"}]}`)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer server.Close()
+
+	ctx, _, _, _, stdErr := createTestCtx()
+	out := bytes.NewBufferString("")
+	ctx.Out = out
+	ctx.Env = &env.Env{Domain: server.URL, Password: "secret", ThemeID: "123", Directory: filepath.Join("_testdata", "projectdir"), APICallLimit: 100}
+	ctx.Flags.JSON = true
+	ctx.Flags.Backup = dir
+	client, err := shopify.NewClient(ctx.Env)
+	assert.Nil(t, err)
+	ctx.Client = &client
+
+	assert.Nil(t, deploy(ctx))
+	assert.Equal(t, "", stdErr.String())
+	assert.Contains(t, out.String(), `"file":"assets/logo.png","environment":"","event":"remove","status":"ok"`)
+	assert.Contains(t, out.String(), `"file":"assets/app.js","environment":"","event":"update","status":"ok"`)
+	assert.NotContains(t, out.String(), `"event":"download"`)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v1"
+
+	"github.com/Shopify/themekit/src/cmdutil"
+	"github.com/Shopify/themekit/src/env"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the theme kit configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the resolved configuration",
+	Long: `Show will print the configuration that commands would run with after
+ the config file, environment variables and flags have been merged together.
+ Secrets are masked in the output. Use --json to print json instead of yaml.
+ `,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		envs, err := cmdutil.ResolveEnvironments(flags)
+		if err != nil {
+			return err
+		}
+		return showConfig(os.Stdout, envs, flags.JSON)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+}
+
+func showConfig(out io.Writer, envs []*env.Env, asJSON bool) error {
+	resolved := map[string]env.Env{}
+	for _, e := range envs {
+		resolved[e.Name] = e.Redacted()
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if asJSON {
+		data, err = json.MarshalIndent(resolved, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(resolved)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(data)
+	return err
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Shopify/themekit/src/env"
+)
+
+func TestShowConfig(t *testing.T) {
+	envs := []*env.Env{{Name: "development", Domain: "shop.myshopify.com", Password: "secret", ThemeID: "123"}}
+
+	out := bytes.NewBufferString("")
+	err := showConfig(out, envs, false)
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "development:\n")
+	assert.Contains(t, out.String(), "store: shop.myshopify.com")
+	assert.Contains(t, out.String(), "theme_id: \"123\"")
+	assert.NotContains(t, out.String(), "secret")
+
+	out.Reset()
+	err = showConfig(out, envs, true)
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), `"store": "shop.myshopify.com"`)
+	assert.Contains(t, out.String(), `"password": "********"`)
+	assert.NotContains(t, out.String(), "secret")
+	assert.Equal(t, "secret", envs[0].Password)
+}
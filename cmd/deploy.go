@@ -2,10 +2,11 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
-	"sync"
+	"strings"
 	"text/template"
 
 	"github.com/spf13/cobra"
@@ -18,6 +19,9 @@ import (
 
 const settingsDataKey = "config/settings_data.json"
 
+// isCaseInsensitiveFS is a variable so that tests can simulate a case insensitive filesystem
+var isCaseInsensitiveFS = file.IsCaseInsensitive
+
 var compiledFilenameWarning = template.Must(template.New("compiledFilenamesWarning").Parse(
 	`[{{.EnvName}}] You have file names that will conflict with each other.
 If you have files named [filename].js.liquid or [filename].scss.liquid,
@@ -41,7 +45,8 @@ var deployCmd = &cobra.Command{
  If deploy is not provided with file names then it will deploy all
  the files on shopify with your local files. Any files that do not
  exist on your local machine will be removed from shopify unless the --nodelete
- flag is passed
+ flag is passed. When file names are provided, pass --sync-deletes to also remove
+ the files under those paths that no longer exist locally.
 
  For more information, refer to https://shopify.dev/tools/theme-kit/command-reference#deploy.
  `,
@@ -55,42 +60,56 @@ var deployCmd = &cobra.Command{
 func deploy(ctx *cmdutil.Ctx) error {
 	if ctx.Env.ReadOnly {
 		return fmt.Errorf("[%s] environment is readonly", colors.Green(ctx.Env.Name))
+	} else if ctx.Flags.WithSettings && ctx.Flags.NoSettings {
+		return fmt.Errorf("[%s] --with-settings and --no-settings cannot be used together", colors.Green(ctx.Env.Name))
+	} else if ctx.Flags.SyncDeletes && ctx.Flags.NoDelete {
+		return fmt.Errorf("[%s] --sync-deletes and --nodelete cannot be used together", colors.Green(ctx.Env.Name))
 	}
 
-	assetsActions, err := generateActions(ctx)
+	assetsActions, remoteChecksums, err := generateActions(ctx)
 	if err != nil {
 		return err
 	}
 
-	var deployGroup sync.WaitGroup
-	ctx.StartProgress(len(assetsActions))
-	for path, op := range assetsActions {
-		if path == settingsDataKey {
-			defer perform(ctx, path, op, "")
-			continue
+	if ctx.Flags.DryRun {
+		ctx.DisableSummary()
+		plan := newDeployPlan(assetsActions, remoteChecksums)
+		if ctx.Flags.JSON {
+			return writeDeployPlan(ctx, plan)
+		}
+		printDeployPlan(ctx, plan)
+		return nil
+	}
+
+	if ctx.Flags.Backup != "" {
+		remoteKeys := []string{}
+		for key := range remoteChecksums {
+			remoteKeys = append(remoteKeys, key)
+		}
+		if _, err := backupRemote(ctx, remoteKeys); err != nil {
+			return fmt.Errorf("[%s] backup failed so nothing was deployed: %s", colors.Green(ctx.Env.Name), err)
 		}
-		deployGroup.Add(1)
-		go func(path string, op file.Op) {
-			defer deployGroup.Done()
-			perform(ctx, path, op, "")
-		}(path, op)
 	}
 
-	deployGroup.Wait()
+	ctx.StartProgress(len(assetsActions))
+	performAll(ctx, assetsActions)
+	notifyChanges(ctx, newNotifyAdapter(ctx.Env.Notify), "deploy", assetsActions)
 
 	return nil
 }
 
-func generateActions(ctx *cmdutil.Ctx) (map[string]file.Op, error) {
+func generateActions(ctx *cmdutil.Ctx) (map[string]file.Op, map[string]string, error) {
 	assetsActions := map[string]file.Op{}
 	pathsToChecksums := map[string]string{}
 
 	remoteFiles, err := ctx.Client.GetAllAssets()
 	if err != nil {
-		return assetsActions, err
+		return assetsActions, pathsToChecksums, err
 	}
 	for _, remoteAsset := range remoteFiles {
-		if len(ctx.Args) == 0 && !ctx.Flags.NoDelete {
+		// when deploying specific paths, remote files under them are only removed with --sync-deletes
+		inScope := len(ctx.Args) == 0 || (ctx.Flags.SyncDeletes && matchesArgs(remoteAsset.Key, ctx.Args))
+		if inScope && !ctx.Flags.NoDelete {
 			assetsActions[remoteAsset.Key] = file.Remove
 		}
 		pathsToChecksums[remoteAsset.Key] = remoteAsset.Checksum
@@ -98,23 +117,133 @@ func generateActions(ctx *cmdutil.Ctx) (map[string]file.Op, error) {
 
 	localAssets, err := shopify.FindAssets(ctx.Env, ctx.Args...)
 	if err != nil {
-		return assetsActions, err
+		return assetsActions, pathsToChecksums, err
 	}
 
 	problemAssets := compileAssetFilenames(localAssets)
 	if len(problemAssets) > 0 {
-		return assetsActions, compiledAssetWarning(ctx.Env.Name, problemAssets)
+		return assetsActions, pathsToChecksums, compiledAssetWarning(ctx.Env.Name, problemAssets)
 	}
 
+	remoteKeys := remoteKeysByCase(ctx, pathsToChecksums)
 	for _, asset := range localAssets {
+		_, exactMatch := pathsToChecksums[asset.Key]
+		if remoteKey, found := remoteKeys[strings.ToLower(asset.Key)]; found && !exactMatch {
+			ctx.Log.Printf("[%s] %s %s is %s on shopify, using the remote name", colors.Green(ctx.Env.Name), colors.Yellow("warn"), colors.Blue(asset.Key), colors.Blue(remoteKey))
+			asset.Key = remoteKey
+		}
+
 		var path = asset.Key
-		if asset.Checksum != "" && (asset.Checksum == pathsToChecksums[asset.Key]) {
+		if asset.Ignored() {
+			delete(assetsActions, path)
+			ctx.Log.Printf("[%s] %s %s (themekit:ignore)", colors.Green(ctx.Env.Name), colors.Cyan("Skipped"), colors.Blue(path))
+		} else if asset.OverMaxFileSize(ctx.Env) {
+			delete(assetsActions, path)
+			ctx.Log.Printf("[%s] %s %s (larger than max_file_size)", colors.Green(ctx.Env.Name), colors.Yellow("Skipped"), colors.Blue(path))
+		} else if !ctx.Flags.Force && asset.Checksum != "" && (asset.Checksum == pathsToChecksums[asset.Key]) {
 			assetsActions[path] = file.Skip
 		} else {
 			assetsActions[path] = file.Update
 		}
 	}
-	return assetsActions, nil
+
+	// settings data is always performed last, these flags decide if it is touched at all
+	if ctx.Flags.NoSettings {
+		delete(assetsActions, settingsDataKey)
+	} else if op, found := assetsActions[settingsDataKey]; found && op == file.Skip && ctx.Flags.WithSettings {
+		assetsActions[settingsDataKey] = file.Update
+	}
+
+	return assetsActions, pathsToChecksums, nil
+}
+
+// remoteKeysByCase maps the lower case remote keys to the remote key when the
+// project is on a case insensitive filesystem, so that a local file is compared to
+// the remote asset it would overwrite instead of being uploaded as a new one.
+func remoteKeysByCase(ctx *cmdutil.Ctx, remoteChecksums map[string]string) map[string]string {
+	remoteKeys := map[string]string{}
+	if !isCaseInsensitiveFS(ctx.Env.Directory) {
+		return remoteKeys
+	}
+
+	for key := range remoteChecksums {
+		folded := strings.ToLower(key)
+		if other, found := remoteKeys[folded]; found {
+			ctx.Log.Printf("[%s] %s %s and %s only differ by case and cannot both exist locally", colors.Green(ctx.Env.Name), colors.Yellow("warn"), colors.Blue(other), colors.Blue(key))
+			continue
+		}
+		remoteKeys[folded] = key
+	}
+	return remoteKeys
+}
+
+// deployPlan describes the changes that a deploy would make to the remote theme
+type deployPlan struct {
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+	Removed []string `json:"removed"`
+	Skipped []string `json:"skipped"`
+}
+
+func newDeployPlan(actions map[string]file.Op, remoteChecksums map[string]string) deployPlan {
+	plan := deployPlan{Created: []string{}, Updated: []string{}, Removed: []string{}, Skipped: []string{}}
+	for path, op := range actions {
+		switch op {
+		case file.Update:
+			if _, onRemote := remoteChecksums[path]; onRemote {
+				plan.Updated = append(plan.Updated, path)
+			} else {
+				plan.Created = append(plan.Created, path)
+			}
+		case file.Remove:
+			plan.Removed = append(plan.Removed, path)
+		case file.Skip:
+			plan.Skipped = append(plan.Skipped, path)
+		}
+	}
+	sort.Strings(plan.Created)
+	sort.Strings(plan.Updated)
+	sort.Strings(plan.Removed)
+	sort.Strings(plan.Skipped)
+	return plan
+}
+
+func printDeployPlan(ctx *cmdutil.Ctx, plan deployPlan) {
+	ctx.Log.Printf("[%s] Dry run, no changes will be made to theme %s", colors.Green(ctx.Env.Name), colors.Yellow(ctx.Env.ThemeID))
+	sections := []struct {
+		title string
+		color func(...interface{}) string
+		paths []string
+	}{
+		{title: "Created", color: colors.Green, paths: plan.Created},
+		{title: "Updated", color: colors.Yellow, paths: plan.Updated},
+		{title: "Removed", color: colors.Red, paths: plan.Removed},
+	}
+	for _, section := range sections {
+		if len(section.paths) == 0 {
+			continue
+		}
+		ctx.Log.Printf("%s:", section.title)
+		for _, path := range section.paths {
+			ctx.Log.Printf("\t%s", section.color(path))
+		}
+	}
+	ctx.Log.Printf("[%s] %v created, %v updated, %v removed, %v unchanged",
+		colors.Green(ctx.Env.Name), len(plan.Created), len(plan.Updated), len(plan.Removed), len(plan.Skipped))
+}
+
+// writeDeployPlan outputs the plan as a single line of json without any colors so
+// that tooling can check the changes a deploy would make.
+func writeDeployPlan(ctx *cmdutil.Ctx, plan deployPlan) error {
+	return json.NewEncoder(ctx.Out).Encode(struct {
+		Environment string `json:"environment"`
+		ThemeID     string `json:"theme_id"`
+		deployPlan
+	}{
+		Environment: ctx.Env.Name,
+		ThemeID:     ctx.Env.ThemeID,
+		deployPlan:  plan,
+	})
 }
 
 func compileAssetFilenames(assets []shopify.Asset) (problemAssets []string) {
@@ -141,3 +270,40 @@ func compiledAssetWarning(env string, filenames []string) error {
 	}{EnvName: colors.Yellow(env), FileNames: filenames})
 	return errors.New(tpl.String())
 }
+
+// validateSettingsData makes sure that the settings data is valid json before it
+// is uploaded because a broken settings file is hard to recover on the live theme.
+func validateSettingsData(asset shopify.Asset) error {
+	if strings.TrimSpace(asset.Value) == "" {
+		return nil
+	}
+
+	var data map[string]interface{}
+	err := json.Unmarshal([]byte(asset.Value), &data)
+	if err == nil {
+		return nil
+	}
+
+	var offset int64
+	switch jsonErr := err.(type) {
+	case *json.SyntaxError:
+		offset = jsonErr.Offset
+	case *json.UnmarshalTypeError:
+		offset = jsonErr.Offset
+	default:
+		return fmt.Errorf("invalid json in %s: %s", asset.Key, err)
+	}
+	line, column := jsonPosition(asset.Value, offset)
+	return fmt.Errorf("invalid json in %s at line %v, column %v: %s", asset.Key, line, column, err)
+}
+
+// jsonPosition converts a byte offset from a json error into a line and column
+func jsonPosition(value string, offset int64) (line, column int) {
+	if offset > int64(len(value)) {
+		offset = int64(len(value))
+	}
+	before := value[:offset]
+	line = strings.Count(before, "\n") + 1
+	column = len(before) - strings.LastIndex(before, "\n")
+	return line, column
+}
@@ -3,6 +3,8 @@ package cmd
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -10,6 +12,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/Shopify/themekit/src/colors"
+	"github.com/Shopify/themekit/src/env"
 	"github.com/Shopify/themekit/src/file"
 	"github.com/Shopify/themekit/src/shopify"
 )
@@ -19,7 +22,7 @@ func TestUploadSingleFile(t *testing.T) {
 	ctx.Args = []string{"templates/layout.liquid"}
 	ctx.Flags.NoDelete = true
 	client.On("GetAllAssets").Return([]shopify.Asset{{Key: "templates/layout.liquid"}}, nil)
-	client.On("UpdateAsset", shopify.Asset{Key: "templates/layout.liquid"}, "").Return(nil)
+	client.On("UpdateAsset", mock.Anything, shopify.Asset{Key: "templates/layout.liquid"}, "").Return(nil)
 	err := deploy(ctx)
 	assert.NotNil(t, err)
 }
@@ -29,7 +32,7 @@ func TestUploadWithReadOnlyOption(t *testing.T) {
 	ctx.Args = []string{"templates/layout.liquid"}
 	ctx.Flags.NoDelete = true
 	ctx.Env.ReadOnly = true
-	client.On("UpdateAsset", shopify.Asset{Key: "templates/layout.liquid"}, "").Return(nil)
+	client.On("UpdateAsset", mock.Anything, shopify.Asset{Key: "templates/layout.liquid"}, "").Return(nil)
 	err := deploy(ctx)
 	if assert.NotNil(t, err) {
 		assert.Contains(t, err.Error(), "environment is readonly")
@@ -44,7 +47,7 @@ func TestUploadWithVerboseOptions(t *testing.T) {
 	ctx.Flags.Verbose = true
 	client.On("GetAllAssets").Return([]shopify.Asset{{Key: "assets/app.js"}}, nil)
 	// This checksum corresponds to a zero-byte file
-	client.On("UpdateAsset", shopify.Asset{Key: "assets/app.js", Checksum: "d41d8cd98f00b204e9800998ecf8427e"}, "").Return(nil)
+	client.On("UpdateAsset", mock.Anything, shopify.Asset{Key: "assets/app.js", Checksum: "d41d8cd98f00b204e9800998ecf8427e"}, "").Return(nil)
 	err := deploy(ctx)
 	assert.Nil(t, err)
 	assert.Contains(t, stdOut.String(), "Updated assets/app.js")
@@ -56,7 +59,7 @@ func TestUploadAllFiles(t *testing.T) {
 	ctx.Flags.Verbose = true
 	ctx.Flags.NoDelete = true
 	client.On("GetAllAssets").Return([]shopify.Asset{{Key: "config/settings_data.json"}}, nil)
-	client.On("UpdateAsset", mock.MatchedBy(func(a shopify.Asset) bool { return true }), "").Return(nil)
+	client.On("UpdateAsset", mock.Anything, mock.MatchedBy(func(a shopify.Asset) bool { return true }), "").Return(nil)
 	err := deploy(ctx)
 	assert.Nil(t, err)
 	assert.Contains(t, stdOut.String(), "Updated config/settings_data.json")
@@ -69,7 +72,7 @@ func TestUploadForSkipFileWhenChecksumsMatch(t *testing.T) {
 	ctx.Flags.NoDelete = true
 	client.On("GetAllAssets").Return([]shopify.Asset{{Key: "config/settings_data.json", Checksum: "d41d8cd98f00b204e9800998ecf8427e"}}, nil)
 	// the _testdirectory contains two assets. We expect one to be uploaded, one to be skipped.
-	client.On("UpdateAsset", shopify.Asset{Key: "assets/app.js", Checksum: "d41d8cd98f00b204e9800998ecf8427e"}, "").Return(nil)
+	client.On("UpdateAsset", mock.Anything, shopify.Asset{Key: "assets/app.js", Checksum: "d41d8cd98f00b204e9800998ecf8427e"}, "").Return(nil)
 	err := deploy(ctx)
 	assert.Nil(t, err)
 	assert.Contains(t, stdOut.String(), "Skipped config/settings_data.json")
@@ -82,7 +85,7 @@ func TestUploadForDoNotSkipWhenChecksumsDiffer(t *testing.T) {
 	ctx.Flags.Verbose = true
 	ctx.Flags.NoDelete = true
 	client.On("GetAllAssets").Return([]shopify.Asset{{Key: "config/settings_data.json", Checksum: "abc123"}}, nil)
-	client.On("UpdateAsset", mock.MatchedBy(func(a shopify.Asset) bool { return true }), "").Return(nil)
+	client.On("UpdateAsset", mock.Anything, mock.MatchedBy(func(a shopify.Asset) bool { return true }), "").Return(nil)
 	err := deploy(ctx)
 	assert.Nil(t, err)
 	assert.Contains(t, stdOut.String(), "Updated config/settings_data.json")
@@ -93,8 +96,8 @@ func TestReplace(t *testing.T) {
 	ctx.Flags.Verbose = true
 	ctx.Env.Directory = filepath.Join("_testdata", "projectdir")
 	client.On("GetAllAssets").Return([]shopify.Asset{{Key: "assets/logo.png"}}, nil)
-	client.On("UpdateAsset", mock.MatchedBy(func(shopify.Asset) bool { return true }), "").Return(nil).Times(2)
-	client.On("DeleteAsset", mock.MatchedBy(func(shopify.Asset) bool { return true })).Return(nil).Once()
+	client.On("UpdateAsset", mock.Anything, mock.MatchedBy(func(shopify.Asset) bool { return true }), "").Return(nil).Times(2)
+	client.On("DeleteAsset", mock.Anything, mock.MatchedBy(func(shopify.Asset) bool { return true })).Return(nil).Once()
 	err := deploy(ctx)
 	assert.Nil(t, err)
 	assert.Contains(t, stdOut.String(), "Updated config/settings_data.json")
@@ -108,11 +111,68 @@ func TestReplace(t *testing.T) {
 	}
 }
 
+func TestDeploySettingsFlags(t *testing.T) {
+	ctx, _, _, _, _ := createTestCtx()
+	ctx.Flags.WithSettings = true
+	ctx.Flags.NoSettings = true
+	err := deploy(ctx)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "cannot be used together")
+	}
+}
+
+func TestDeployDryRun(t *testing.T) {
+	ctx, client, _, stdOut, _ := createTestCtx()
+	ctx.Env.Directory = filepath.Join("_testdata", "projectdir")
+	ctx.Flags.DryRun = true
+	client.On("GetAllAssets").Return([]shopify.Asset{{Key: "assets/logo.png"}, {Key: "config/settings_data.json", Checksum: "abc123"}}, nil)
+	err := deploy(ctx)
+	assert.Nil(t, err)
+	client.AssertNotCalled(t, "UpdateAsset", mock.Anything, mock.Anything)
+	client.AssertNotCalled(t, "DeleteAsset", mock.Anything)
+	assert.Contains(t, stdOut.String(), "Dry run")
+	assert.Contains(t, stdOut.String(), "Created:\n\t"+colors.Green("assets/app.js"))
+	assert.Contains(t, stdOut.String(), "Updated:\n\t"+colors.Yellow("config/settings_data.json"))
+	assert.Contains(t, stdOut.String(), "Removed:\n\t"+colors.Red("assets/logo.png"))
+}
+
+func TestDeployDryRunJSON(t *testing.T) {
+	ctx, client, _, stdOut, _ := createTestCtx()
+	out := bytes.NewBufferString("")
+	ctx.Out = out
+	ctx.Env.Name = "production"
+	ctx.Env.ThemeID = "123"
+	ctx.Env.Directory = filepath.Join("_testdata", "projectdir")
+	ctx.Flags.DryRun = true
+	ctx.Flags.JSON = true
+	client.On("GetAllAssets").Return([]shopify.Asset{{Key: "assets/logo.png"}, {Key: "config/settings_data.json", Checksum: "abc123"}}, nil)
+	assert.Nil(t, deploy(ctx))
+	assert.Equal(t, "", stdOut.String())
+	assert.Equal(t, `{"environment":"production","theme_id":"123","created":["assets/app.js"],"updated":["config/settings_data.json"],"removed":["assets/logo.png"],"skipped":[]}`+"\n", out.String())
+}
+
+func TestNewDeployPlan(t *testing.T) {
+	actions := map[string]file.Op{
+		"assets/b.js":         file.Update,
+		"assets/a.js":         file.Update,
+		"templates/x.liquid":  file.Update,
+		"snippets/old.liquid": file.Remove,
+		"layout/theme.liquid": file.Skip,
+	}
+	remote := map[string]string{"templates/x.liquid": "abc", "snippets/old.liquid": "", "layout/theme.liquid": "def"}
+	assert.Equal(t, deployPlan{
+		Created: []string{"assets/a.js", "assets/b.js"},
+		Updated: []string{"templates/x.liquid"},
+		Removed: []string{"snippets/old.liquid"},
+		Skipped: []string{"layout/theme.liquid"},
+	}, newDeployPlan(actions, remote))
+}
+
 func TestGenerateActions(t *testing.T) {
 	ctx, client, _, _, _ := createTestCtx()
 	ctx.Env.Directory = filepath.Join("_testdata", "projectdir")
 	client.On("GetAllAssets").Return([]shopify.Asset{{Key: "assets/logo.png"}}, nil)
-	actions, err := generateActions(ctx)
+	actions, _, err := generateActions(ctx)
 	assert.Nil(t, err)
 	assert.Equal(t, actions["assets/logo.png"], file.Remove)
 	assert.Equal(t, actions["config/settings_data.json"], file.Update)
@@ -121,10 +181,39 @@ func TestGenerateActions(t *testing.T) {
 	_, found := actions["assets/.gitkeep"]
 	assert.False(t, found)
 
+	ctx, client, _, _, _ = createTestCtx()
+	ctx.Env.Directory = filepath.Join("_testdata", "projectdir")
+	ctx.Flags.NoSettings = true
+	client.On("GetAllAssets").Return([]shopify.Asset{{Key: "config/settings_data.json"}}, nil)
+	actions, _, err = generateActions(ctx)
+	assert.Nil(t, err)
+	_, found = actions["config/settings_data.json"]
+	assert.False(t, found)
+
+	settingsAsset, _ := shopify.ReadAsset(ctx.Env, "config/settings_data.json")
+	ctx, client, _, _, _ = createTestCtx()
+	ctx.Env.Directory = filepath.Join("_testdata", "projectdir")
+	client.On("GetAllAssets").Return([]shopify.Asset{{Key: "config/settings_data.json", Checksum: settingsAsset.Checksum}}, nil)
+	actions, _, err = generateActions(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, file.Skip, actions["config/settings_data.json"])
+	ctx.Flags.WithSettings = true
+	actions, _, err = generateActions(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, file.Update, actions["config/settings_data.json"])
+
+	ctx, client, _, _, _ = createTestCtx()
+	ctx.Env.Directory = filepath.Join("_testdata", "projectdir")
+	ctx.Flags.Force = true
+	client.On("GetAllAssets").Return([]shopify.Asset{{Key: "config/settings_data.json", Checksum: settingsAsset.Checksum}}, nil)
+	actions, _, err = generateActions(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, file.Update, actions["config/settings_data.json"])
+
 	ctx, client, _, _, _ = createTestCtx()
 	ctx.Env.Directory = filepath.Join("_testdata", "projectdir")
 	client.On("GetAllAssets").Return([]shopify.Asset{}, fmt.Errorf("server error"))
-	_, err = generateActions(ctx)
+	_, _, err = generateActions(ctx)
 	if assert.NotNil(t, err) {
 		assert.Contains(t, err.Error(), "server error")
 	}
@@ -132,14 +221,14 @@ func TestGenerateActions(t *testing.T) {
 	ctx, client, _, _, _ = createTestCtx()
 	ctx.Env.Directory = "not there"
 	client.On("GetAllAssets").Return([]shopify.Asset{}, nil)
-	_, err = generateActions(ctx)
+	_, _, err = generateActions(ctx)
 	assert.NotNil(t, err)
 
 	ctx, client, _, _, _ = createTestCtx()
 	ctx.Env.Name = "development"
 	ctx.Env.Directory = filepath.Join("_testdata", "badprojectdir")
 	client.On("GetAllAssets").Return([]shopify.Asset{}, nil)
-	actions, err = generateActions(ctx)
+	actions, _, err = generateActions(ctx)
 	assert.NotNil(t, err)
 	var tpl bytes.Buffer
 	compiledFilenameWarning.Execute(&tpl, struct {
@@ -149,6 +238,82 @@ func TestGenerateActions(t *testing.T) {
 	assert.Equal(t, tpl.String(), err.Error())
 }
 
+func TestGenerateActionsSyncDeletes(t *testing.T) {
+	remoteAssets := []shopify.Asset{{Key: "assets/app.js"}, {Key: "assets/old.js"}, {Key: "snippets/old.liquid"}}
+
+	ctx, client, _, _, _ := createTestCtx()
+	ctx.Env.Directory = filepath.Join("_testdata", "projectdir")
+	ctx.Args = []string{"assets"}
+	client.On("GetAllAssets").Return(remoteAssets, nil)
+	actions, _, err := generateActions(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]file.Op{"assets/app.js": file.Update}, actions)
+
+	ctx, client, _, _, _ = createTestCtx()
+	ctx.Env.Directory = filepath.Join("_testdata", "projectdir")
+	ctx.Args = []string{"assets"}
+	ctx.Flags.SyncDeletes = true
+	client.On("GetAllAssets").Return(remoteAssets, nil)
+	actions, _, err = generateActions(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]file.Op{"assets/app.js": file.Update, "assets/old.js": file.Remove}, actions)
+
+	ctx, _, _, _, _ = createTestCtx()
+	ctx.Flags.SyncDeletes = true
+	ctx.Flags.NoDelete = true
+	err = deploy(ctx)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "--sync-deletes and --nodelete cannot be used together")
+	}
+}
+
+func TestGenerateActionsMaxFileSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "themekit-deploy")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "assets"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "assets", "big.js"), []byte("0123456789"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "assets", "small.js"), []byte("01234"), 0644))
+
+	ctx, client, _, stdOut, _ := createTestCtx()
+	ctx.Env.Directory = dir
+	ctx.Env.MaxFileSize = 5
+	client.On("GetAllAssets").Return([]shopify.Asset{{Key: "assets/big.js"}}, nil)
+	actions, _, err := generateActions(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]file.Op{"assets/small.js": file.Update}, actions)
+	assert.Contains(t, stdOut.String(), "assets/big.js (larger than max_file_size)")
+}
+
+func TestGenerateActionsCaseInsensitive(t *testing.T) {
+	original := isCaseInsensitiveFS
+	defer func() { isCaseInsensitiveFS = original }()
+
+	appAsset, _ := shopify.ReadAsset(&env.Env{Directory: filepath.Join("_testdata", "projectdir")}, "assets/app.js")
+	remoteAssets := []shopify.Asset{{Key: "assets/App.js", Checksum: appAsset.Checksum}, {Key: "assets/Logo.png"}, {Key: "assets/logo.PNG"}}
+
+	isCaseInsensitiveFS = func(string) bool { return true }
+	ctx, client, _, stdOut, _ := createTestCtx()
+	ctx.Env.Directory = filepath.Join("_testdata", "projectdir")
+	client.On("GetAllAssets").Return(remoteAssets, nil)
+	actions, _, err := generateActions(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, file.Skip, actions["assets/App.js"])
+	_, found := actions["assets/app.js"]
+	assert.False(t, found)
+	assert.Contains(t, stdOut.String(), "assets/app.js is assets/App.js on shopify")
+	assert.Contains(t, stdOut.String(), "only differ by case")
+
+	isCaseInsensitiveFS = func(string) bool { return false }
+	ctx, client, _, _, _ = createTestCtx()
+	ctx.Env.Directory = filepath.Join("_testdata", "projectdir")
+	client.On("GetAllAssets").Return(remoteAssets, nil)
+	actions, _, err = generateActions(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, file.Remove, actions["assets/App.js"])
+	assert.Equal(t, file.Update, actions["assets/app.js"])
+}
+
 func TestCompileAssetFilenames(t *testing.T) {
 	input := []shopify.Asset{
 		{Key: "assets/app.js"},
@@ -178,3 +343,24 @@ func TestCompiledAssetWarning(t *testing.T) {
 
 	assert.Equal(t, tpl.String(), compiledAssetWarning("development", filenames).Error())
 }
+
+func TestValidateSettingsData(t *testing.T) {
+	testcases := []struct {
+		value, err string
+	}{
+		{value: `{"current": "Default"}`},
+		{value: "{\n  \"current\": \"Default\",\n}", err: "invalid json in config/settings_data.json at line 3, column 2"},
+		{value: `["current"]`, err: "invalid json in config/settings_data.json at line 1, column 2"},
+		{value: `{"current": `, err: "invalid json in config/settings_data.json"},
+		{value: ``},
+	}
+
+	for _, testcase := range testcases {
+		err := validateSettingsData(shopify.Asset{Key: settingsDataKey, Value: testcase.value})
+		if testcase.err == "" {
+			assert.Nil(t, err)
+		} else if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), testcase.err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+
+	"github.com/Shopify/themekit/src/cmdutil"
+	"github.com/Shopify/themekit/src/colors"
+	"github.com/Shopify/themekit/src/shopify"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <filenames>",
+	Short: "Show the differences between local files and the files on shopify",
+	Long: `Diff will download the remote version of each file and print a unified
+ diff against the local file, along with which side was changed more recently.
+ Binary files are only compared by checksum.
+ `,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("please specify at least one file to diff")
+		}
+		// diffing does not change the theme so it should not care about the live theme
+		flags.AllowLive = true
+		return cmdutil.ForSingleClient(flags, args, diff)
+	},
+}
+
+func diff(ctx *cmdutil.Ctx) error {
+	ctx.DisableSummary()
+
+	for _, path := range ctx.Args {
+		path = shopify.NormalizeKey(path)
+		localAsset, localErr := shopify.ReadAsset(ctx.Env, path)
+		remoteAsset, remoteErr := ctx.Client.GetAsset(context.Background(), path)
+		if localErr != nil && remoteErr != nil {
+			return fmt.Errorf("[%s] %s does not exist locally or on shopify", colors.Green(ctx.Env.Name), path)
+		} else if remoteErr != nil && remoteErr != shopify.ErrNotPartOfTheme {
+			return remoteErr
+		}
+
+		if localAsset.Attachment != "" || remoteAsset.Attachment != "" {
+			if localAsset.Checksum == remoteAsset.Checksum && localErr == nil && remoteErr == nil {
+				fmt.Fprintf(ctx.Out, "[%s] %s is the same locally and on shopify\n", colors.Green(ctx.Env.Name), colors.Blue(path))
+			} else {
+				fmt.Fprintf(ctx.Out, "[%s] Binary file %s differs\n", colors.Green(ctx.Env.Name), colors.Blue(path))
+			}
+			continue
+		}
+
+		output, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(remoteAsset.Value),
+			B:        difflib.SplitLines(localAsset.Value),
+			FromFile: "remote/" + path,
+			ToFile:   "local/" + path,
+			Context:  3,
+		})
+		if err != nil {
+			return err
+		} else if output == "" {
+			fmt.Fprintf(ctx.Out, "[%s] %s is the same locally and on shopify\n", colors.Green(ctx.Env.Name), colors.Blue(path))
+			continue
+		}
+
+		if newer := newerSide(ctx, path, remoteAsset); newer != "" {
+			fmt.Fprintf(ctx.Out, "[%s] %s was changed more recently %s\n", colors.Green(ctx.Env.Name), colors.Blue(path), newer)
+		}
+		fmt.Fprint(ctx.Out, output)
+	}
+
+	return nil
+}
+
+// newerSide will compare the local modification time to the remote updated at
+// time and describe which one is newer. Remote times only have second precision
+// so the local time is truncated to seconds, and if both times are equal or either
+// time is unknown it returns an empty string because neither side is newer.
+func newerSide(ctx *cmdutil.Ctx, path string, remoteAsset shopify.Asset) string {
+	info, err := os.Stat(filepath.Join(ctx.Env.Directory, path))
+	if err != nil {
+		return ""
+	}
+	updatedAt, err := time.Parse(time.RFC3339, remoteAsset.UpdatedAt)
+	localTime := info.ModTime().Truncate(time.Second)
+	if err != nil || updatedAt.Equal(localTime) {
+		return ""
+	} else if updatedAt.After(localTime) {
+		return "on shopify"
+	}
+	return "locally"
+}
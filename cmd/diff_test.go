@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Shopify/themekit/src/shopify"
+)
+
+func TestDiff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "themekit-diff")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "templates"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "templates", "index.liquid"), []byte("<h1>hello</h1>\n<p>local</p>\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "templates", "same.liquid"), []byte("same\n"), 0644))
+
+	ctx, client, _, stdOut, _ := createTestCtx()
+	ctx.Env.Directory = dir
+	ctx.Args = []string{"templates/index.liquid", "templates/same.liquid"}
+	client.On("GetAsset", mock.Anything, "templates/index.liquid").Return(shopify.Asset{Key: "templates/index.liquid", Value: "<h1>hello</h1>\n<p>remote</p>\n", UpdatedAt: time.Now().Add(time.Hour).Format(time.RFC3339)}, nil)
+	client.On("GetAsset", mock.Anything, "templates/same.liquid").Return(shopify.Asset{Key: "templates/same.liquid", Value: "same\n"}, nil)
+	assert.Nil(t, diff(ctx))
+	assert.Contains(t, stdOut.String(), "--- remote/templates/index.liquid\n+++ local/templates/index.liquid\n")
+	assert.Contains(t, stdOut.String(), "-<p>remote</p>\n+<p>local</p>\n")
+	assert.Contains(t, stdOut.String(), "was changed more recently on shopify")
+	assert.Contains(t, stdOut.String(), "templates/same.liquid is the same locally and on shopify")
+
+	ctx, client, _, stdOut, _ = createTestCtx()
+	ctx.Env.Directory = dir
+	ctx.Args = []string{"assets/logo.png"}
+	client.On("GetAsset", mock.Anything, "assets/logo.png").Return(shopify.Asset{Key: "assets/logo.png", Attachment: "iVBORw0KGgo=", Checksum: "abc"}, nil)
+	assert.Nil(t, diff(ctx))
+	assert.Contains(t, stdOut.String(), "Binary file assets/logo.png differs")
+
+	ctx, client, _, _, _ = createTestCtx()
+	ctx.Env.Directory = dir
+	ctx.Args = []string{"templates/nope.liquid"}
+	client.On("GetAsset", mock.Anything, "templates/nope.liquid").Return(shopify.Asset{}, shopify.ErrNotPartOfTheme)
+	err = diff(ctx)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "does not exist locally or on shopify")
+	}
+
+	ctx, client, _, _, _ = createTestCtx()
+	ctx.Env.Directory = dir
+	ctx.Args = []string{"templates/index.liquid"}
+	client.On("GetAsset", mock.Anything, "templates/index.liquid").Return(shopify.Asset{}, fmt.Errorf("server error"))
+	assert.EqualError(t, diff(ctx), "server error")
+}
+
+func TestNewerSide(t *testing.T) {
+	dir, err := ioutil.TempDir("", "themekit-diff")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "index.liquid")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("hello"), 0644))
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 600, time.UTC)
+	assert.Nil(t, os.Chtimes(path, modTime, modTime))
+
+	ctx, _, _, _, _ := createTestCtx()
+	ctx.Env.Directory = dir
+	testcases := []struct{ updatedAt, expected string }{
+		{updatedAt: modTime.Add(time.Second).Format(time.RFC3339), expected: "on shopify"},
+		{updatedAt: modTime.Add(-time.Second).Format(time.RFC3339), expected: "locally"},
+		{updatedAt: modTime.Format(time.RFC3339), expected: ""},
+		{updatedAt: "", expected: ""},
+	}
+	for _, testcase := range testcases {
+		assert.Equal(t, testcase.expected, newerSide(ctx, "index.liquid", shopify.Asset{UpdatedAt: testcase.updatedAt}), testcase.updatedAt)
+	}
+	assert.Equal(t, "", newerSide(ctx, "nope.liquid", shopify.Asset{UpdatedAt: modTime.Format(time.RFC3339)}))
+}
@@ -2,9 +2,8 @@ package cmd
 
 import (
 	"fmt"
-	"path/filepath"
 	"strconv"
-	"sync"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -18,7 +17,8 @@ var downloadCmd = &cobra.Command{
 	Short: "Download one or all of the theme files",
 	Long: `Download will download specific files from shopify servers if provided file names.
  If no filenames are provided then download will download every file in the project
- and write them to disk.
+ and write them to disk. File names can be globs, and a name prefixed with ! will
+ exclude the files it matches, for example theme download '**' '!config/*'
 
  For more information, refer to https://shopify.dev/tools/theme-kit/command-reference#download.
  `,
@@ -37,8 +37,6 @@ var downloadCmd = &cobra.Command{
 }
 
 func download(ctx *cmdutil.Ctx) error {
-	var downloadGroup sync.WaitGroup
-
 	assets, err := filesToDownload(ctx)
 	if err != nil {
 		return err
@@ -49,15 +47,7 @@ func download(ctx *cmdutil.Ctx) error {
 	}
 
 	ctx.StartProgress(len(assets))
-	for asset, op := range assets {
-		downloadGroup.Add(1)
-		go func(path string, op file.Op) {
-			defer downloadGroup.Done()
-			perform(ctx, path, op, "")
-		}(asset, op)
-	}
-
-	downloadGroup.Wait()
+	performAll(ctx, assets)
 
 	return nil
 }
@@ -78,17 +68,8 @@ func filesToDownload(ctx *cmdutil.Ctx) (map[string]file.Op, error) {
 	}
 
 	for _, asset := range assets {
-		for _, pattern := range ctx.Args {
-			// These need to be converted to platform specific because filepath.Match
-			// uses platform specific separators
-			pattern = filepath.FromSlash(pattern)
-			filename := filepath.FromSlash(asset.Key)
-			globMatched, _ := filepath.Match(pattern, filename)
-			dirMatched, _ := filepath.Match(pattern+string(filepath.Separator)+"*", filename)
-			fileMatched := filename == pattern
-			if globMatched || dirMatched || fileMatched {
-				fetchableFiles[asset.Key] = downloadFileAction(ctx, asset)
-			}
+		if matchesArgs(asset.Key, ctx.Args) {
+			fetchableFiles[asset.Key] = downloadFileAction(ctx, asset)
 		}
 	}
 
@@ -99,9 +80,43 @@ func filesToDownload(ctx *cmdutil.Ctx) (map[string]file.Op, error) {
 	return fetchableFiles, nil
 }
 
+// matchesArgs will return true if the asset key matches any of the file, directory
+// or glob patterns passed as arguments. Patterns prefixed with ! are subtracted
+// from the matches, if there are only negated patterns then every key that they
+// do not match is matched.
+func matchesArgs(key string, args []string) bool {
+	patterns, negated := []string{}, []string{}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "!") {
+			negated = append(negated, strings.TrimPrefix(arg, "!"))
+		} else {
+			patterns = append(patterns, arg)
+		}
+	}
+	if len(patterns) > 0 && !matchesAny(key, patterns) {
+		return false
+	}
+	return !matchesAny(key, negated)
+}
+
+func matchesAny(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		// Asset keys are always slash separated, so patterns are matched in the
+		// same form and ** can match across directories
+		pattern = shopify.NormalizeKey(pattern)
+		globMatched := file.MatchPath(pattern, key)
+		dirMatched := file.MatchPath(pattern+"/*", key)
+		fileMatched := key == pattern
+		if globMatched || dirMatched || fileMatched {
+			return true
+		}
+	}
+	return false
+}
+
 func downloadFileAction(ctx *cmdutil.Ctx, asset shopify.Asset) file.Op {
 	op := file.Get
-	if asset.Checksum == "" {
+	if asset.Checksum == "" || ctx.Flags.Force {
 		return op
 	}
 	if localAsset, _ := shopify.ReadAsset(ctx.Env, asset.Key); asset.Checksum == localAsset.Checksum {
@@ -25,7 +25,7 @@ func TestDownload(t *testing.T) {
 
 	ctx, client, _, _, stdErr := createTestCtx()
 	client.On("GetAllAssets").Return(allAssets, nil)
-	client.On("GetAsset", mock.MatchedBy(func(string) bool { return true })).
+	client.On("GetAsset", mock.Anything, mock.MatchedBy(func(string) bool { return true })).
 		Return(shopify.Asset{Key: "assets/logo.png"}, nil).
 		Times(len(allAssets))
 	err := download(ctx)
@@ -41,7 +41,7 @@ func TestDownload(t *testing.T) {
 
 	ctx, client, _, _, stdErr = createTestCtx()
 	client.On("GetAllAssets").Return(allAssets, nil)
-	client.On("GetAsset", mock.MatchedBy(func(string) bool { return true })).Return(shopify.Asset{}, fmt.Errorf("asset err"))
+	client.On("GetAsset", mock.Anything, mock.MatchedBy(func(string) bool { return true })).Return(shopify.Asset{}, fmt.Errorf("asset err"))
 	assert.Nil(t, download(ctx))
 	assert.Contains(t, stdErr.String(), "error downloading assets/logo.png")
 }
@@ -62,7 +62,16 @@ func TestFilesToDownload(t *testing.T) {
 		{ret: allOps},
 		{args: []string{"assets/logo.png"}, ret: map[string]file.Op{"assets/logo.png": file.Get}},
 		{args: []string{"assets/*"}, ret: map[string]file.Op{"assets/logo.png": file.Get}},
+		{args: []string{"/assets/logo.png"}, ret: map[string]file.Op{"assets/logo.png": file.Get}},
+		{args: []string{`templates\customers\test.liquid`}, ret: map[string]file.Op{"templates/customers/test.liquid": file.Get}},
+		{args: []string{"templates/"}, ret: map[string]file.Op{"templates/test.liquid": file.Get}},
 		{args: []string{"templates"}, ret: map[string]file.Op{"templates/test.liquid": file.Get}},
+		{args: []string{"templates/**"}, ret: map[string]file.Op{"templates/test.liquid": file.Get, "templates/customers/test.liquid": file.Get}},
+		{args: []string{"**/test.liquid"}, ret: map[string]file.Op{"templates/customers/test.liquid": file.Get, "config/test.liquid": file.Get, "layout/test.liquid": file.Get, "snippets/test.liquid": file.Get, "templates/test.liquid": file.Get, "locales/test.liquid": file.Get, "sections/test.liquid": file.Get}},
+		{args: []string{"**", "!config/*"}, ret: map[string]file.Op{"assets/logo.png": file.Get, "templates/customers/test.liquid": file.Get, "layout/test.liquid": file.Get, "snippets/test.liquid": file.Get, "templates/test.liquid": file.Get, "locales/test.liquid": file.Get, "sections/test.liquid": file.Get}},
+		{args: []string{"templates/**", "!templates/customers"}, ret: map[string]file.Op{"templates/test.liquid": file.Get}},
+		{args: []string{"!**/*.liquid"}, ret: map[string]file.Op{"assets/logo.png": file.Get}},
+		{args: []string{"assets/*", "!assets/*.png"}, ret: map[string]file.Op{}, err: "No file paths matched the inputted arguments"},
 		{args: []string{"assets/nope.png"}, ret: map[string]file.Op{}, err: "No file paths matched the inputted arguments"},
 		{args: []string{"assets/nope.png"}, ret: map[string]file.Op{}, respErr: fmt.Errorf("server error"), err: "server error"},
 	}
@@ -94,4 +103,8 @@ func TestFilesToDownloadFileAction(t *testing.T) {
 
 	op = downloadFileAction(ctx, shopify.Asset{Key: "assets/app.js"})
 	assert.Equal(t, file.Get, op)
+
+	ctx.Flags.Force = true
+	op = downloadFileAction(ctx, shopify.Asset{Key: "assets/app.js", Checksum: localAsset.Checksum})
+	assert.Equal(t, file.Get, op)
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"text/template"
 
 	"github.com/spf13/cobra"
@@ -13,6 +14,9 @@ import (
 	"github.com/Shopify/themekit/src/shopify"
 )
 
+// placeholderThemeID is used for operations that do not work on a theme
+const placeholderThemeID = "1337"
+
 var (
 	errNoThemes     = errors.New("No available themes")
 	availableThemes = template.Must(template.New("availableThemes").Parse(`Available theme versions:
@@ -22,11 +26,13 @@ var (
 )
 
 var getCmd = &cobra.Command{
-	Use:   "get",
-	Short: "Get a theme and config from shopify",
+	Use:     "get",
+	Aliases: []string{"bootstrap"},
+	Short:   "Get a theme and config from shopify",
 	Long: `Get will get a theme from shopify and create a config.yml for the theme
  that it accesses. To get a list of all themes that you can access, you can pass in
- a --list flag.
+ a --list flag. The theme can be selected with --themeid, by name with --name, or
+ the published theme can be selected with --live or --themeid=live.
 
  For more information, refer to https://shopify.dev/tools/theme-kit/command-reference#get.
  `,
@@ -35,12 +41,22 @@ var getCmd = &cobra.Command{
 		flags.AllowLive = true
 		if flags.List {
 			return listThemes(flags, args)
-		} else if flags.Live {
+		} else if flags.Live || strings.ToLower(flags.ThemeID) == "live" {
 			theme, err := getLiveTheme(flags, args)
 			if err != nil {
 				return err
 			}
 			flags.ThemeID = strconv.Itoa(int(theme.ID))
+		} else if flags.Name != "" {
+			themes, err := getDefaultThemes(flags, args)
+			if err != nil {
+				return err
+			}
+			theme, err := findThemeByName(themes, flags.Name)
+			if err != nil {
+				return err
+			}
+			flags.ThemeID = strconv.Itoa(int(theme.ID))
 		}
 		return cmdutil.ForDefaultClient(flags, args, getTheme)
 	},
@@ -54,7 +70,7 @@ func getTheme(ctx *cmdutil.Ctx) error {
 }
 
 func listThemes(flags cmdutil.Flags, args []string) error {
-	flags.ThemeID = "1337"
+	flags = withoutTheme(flags)
 	themes, err := getDefaultThemes(flags, args)
 	if err != nil {
 		return err
@@ -80,9 +96,27 @@ func getLiveTheme(flags cmdutil.Flags, args []string) (shopify.Theme, error) {
 	return shopify.Theme{}, fmt.Errorf("No live theme found")
 }
 
+// findThemeByName will find the only theme with the name, ignoring case
+func findThemeByName(themes []shopify.Theme, name string) (shopify.Theme, error) {
+	matches := []shopify.Theme{}
+	for _, theme := range themes {
+		if strings.EqualFold(strings.TrimSpace(theme.Name), strings.TrimSpace(name)) {
+			matches = append(matches, theme)
+		}
+	}
+
+	var tpl bytes.Buffer
+	availableThemes.Execute(&tpl, themes)
+	if len(matches) == 0 {
+		return shopify.Theme{}, fmt.Errorf("No theme named %q found. %s", name, tpl.String())
+	} else if len(matches) > 1 {
+		return shopify.Theme{}, fmt.Errorf("More than one theme is named %q, please use --themeid instead. %s", name, tpl.String())
+	}
+	return matches[0], nil
+}
+
 func getDefaultThemes(flags cmdutil.Flags, args []string) ([]shopify.Theme, error) {
-	// This is a hack to get around theme ID validation for the list operation which doesnt need it
-	flags.ThemeID = "1337"
+	flags = withoutTheme(flags)
 	var err error
 	var themes []shopify.Theme
 	return themes, cmdutil.ForDefaultClient(flags, args, func(ctx *cmdutil.Ctx) error {
@@ -96,8 +130,7 @@ func getDefaultThemes(flags cmdutil.Flags, args []string) ([]shopify.Theme, erro
 }
 
 func withThemes(flags cmdutil.Flags, args []string, fn func(ctx *cmdutil.Ctx, themes []shopify.Theme) error) error {
-	// This is a hack to get around theme ID validation for the list operation which doesnt need it
-	flags.ThemeID = "1337"
+	flags = withoutTheme(flags)
 	return cmdutil.ForDefaultClient(flags, args, func(ctx *cmdutil.Ctx) error {
 		themes, err := ctx.Client.Themes()
 		if err != nil {
@@ -108,3 +141,12 @@ func withThemes(flags cmdutil.Flags, args []string, fn func(ctx *cmdutil.Ctx, th
 		return fn(ctx, themes)
 	})
 }
+
+// withoutTheme returns the flags for an operation that does not work on a theme.
+// Env validation requires a theme id so a placeholder is set, and because the
+// placeholder will never exist in the shop the theme check is skipped.
+func withoutTheme(flags cmdutil.Flags) cmdutil.Flags {
+	flags.ThemeID = placeholderThemeID
+	flags.SkipThemeCheck = true
+	return flags
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/Shopify/themekit/src/cmdutil"
 	"github.com/Shopify/themekit/src/env"
 	"github.com/Shopify/themekit/src/shopify"
 )
@@ -41,3 +42,34 @@ func TestGet(t *testing.T) {
 	client.On("GetAllAssets").Return([]shopify.Asset{}, nil)
 	assert.Error(t, getTheme(ctx), "No files to download")
 }
+
+func TestFindThemeByName(t *testing.T) {
+	themes := []shopify.Theme{
+		{ID: 1, Name: "Debut", Role: "main"},
+		{ID: 2, Name: "Dawn"},
+		{ID: 3, Name: "Copy of Dawn"},
+		{ID: 4, Name: "copy of dawn"},
+	}
+
+	theme, err := findThemeByName(themes, "dawn")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), theme.ID)
+
+	_, err = findThemeByName(themes, "Brooklyn")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), `No theme named "Brooklyn" found`)
+		assert.Contains(t, err.Error(), "[1][live] Debut")
+	}
+
+	_, err = findThemeByName(themes, "Copy of Dawn")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "More than one theme")
+	}
+}
+
+func TestWithoutTheme(t *testing.T) {
+	flags := withoutTheme(cmdutil.Flags{Environments: []string{"development"}})
+	assert.Equal(t, placeholderThemeID, flags.ThemeID)
+	assert.True(t, flags.SkipThemeCheck)
+	assert.Equal(t, []string{"development"}, flags.Environments)
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Shopify/themekit/src/shopify"
+)
+
+var (
+	// liquidBlockTags are the tags that have to be closed with an end tag
+	liquidBlockTags = map[string]bool{
+		"capture": true, "case": true, "comment": true, "doc": true, "for": true, "form": true,
+		"if": true, "ifchanged": true, "javascript": true, "paginate": true, "raw": true, "schema": true,
+		"style": true, "stylesheet": true, "tablerow": true, "unless": true,
+	}
+	// liquidRawTags are block tags whose contents are not liquid
+	liquidRawTags = map[string]bool{
+		"comment": true, "doc": true, "javascript": true, "raw": true, "schema": true, "stylesheet": true,
+	}
+	// liquidRawEndTags match the end tag of each raw tag
+	liquidRawEndTags = rawEndTagPatterns(liquidRawTags)
+	// liquidTags are the tags that do not have an end tag
+	liquidTags = map[string]bool{
+		"assign": true, "break": true, "content_for": true, "continue": true, "cycle": true,
+		"decrement": true, "echo": true, "else": true, "elsif": true, "include": true,
+		"increment": true, "layout": true, "liquid": true, "render": true, "section": true,
+		"sections": true, "when": true,
+	}
+)
+
+// openLiquidTag is a block tag that has not been closed yet
+type openLiquidTag struct {
+	name string
+	line int
+}
+
+// lintLiquid will catch the liquid errors that most often break a page, like tags
+// that are never terminated and block tags that are not closed. It is not a full
+// parser so it will not catch every error. Tags it does not know are returned as
+// warnings because shopify may support tags that the linter does not.
+func lintLiquid(asset shopify.Asset) (warnings []string, err error) {
+	value := asset.Value
+	stack := []openLiquidTag{}
+	line, counted := 1, 0
+
+	for offset := 0; offset < len(value); {
+		start := strings.Index(value[offset:], "{")
+		if start < 0 {
+			break
+		}
+		start += offset
+		if start+1 >= len(value) || (value[start+1] != '%' && value[start+1] != '{') {
+			offset = start + 1
+			continue
+		}
+
+		line += strings.Count(value[counted:start], "\n")
+		counted = start
+		closing := "}}"
+		if value[start+1] == '%' {
+			closing = "%}"
+		}
+		end := strings.Index(value[start+2:], closing)
+		if end < 0 {
+			return warnings, liquidError(asset.Key, line, "%s is never terminated with %s", value[start:start+2], closing)
+		}
+		end += start + 2
+		offset = end + 2
+		if closing == "}}" {
+			continue
+		}
+
+		name := liquidTagName(value[start+2 : end])
+		switch {
+		case strings.HasPrefix(name, "end") && liquidBlockTags[strings.TrimPrefix(name, "end")]:
+			opened := strings.TrimPrefix(name, "end")
+			if len(stack) == 0 {
+				return warnings, liquidError(asset.Key, line, "unexpected %s", name)
+			} else if top := stack[len(stack)-1]; top.name != opened {
+				return warnings, liquidError(asset.Key, line, "unexpected %s, expected end%s for the %s on line %v", name, top.name, top.name, top.line)
+			}
+			stack = stack[:len(stack)-1]
+		case liquidRawTags[name]:
+			endTag := liquidRawEndTags[name].FindStringIndex(value[offset:])
+			if endTag == nil {
+				return warnings, liquidError(asset.Key, line, "%s is never closed with end%s", name, name)
+			}
+			// skip the contents, the end tag is then matched like any other
+			stack = append(stack, openLiquidTag{name: name, line: line})
+			offset += endTag[0]
+		case liquidBlockTags[name]:
+			stack = append(stack, openLiquidTag{name: name, line: line})
+		case name == "#" || liquidTags[name]:
+		default:
+			warnings = append(warnings, fmt.Sprintf("unknown liquid tag %s in %s at line %v", name, asset.Key, line))
+		}
+	}
+
+	if len(stack) > 0 {
+		top := stack[len(stack)-1]
+		return warnings, liquidError(asset.Key, top.line, "%s is never closed with end%s", top.name, top.name)
+	}
+	return warnings, nil
+}
+
+// rawEndTagPatterns builds the patterns that find the end of each raw tag, allowing
+// for whitespace control characters like {%- endraw -%}.
+func rawEndTagPatterns(tags map[string]bool) map[string]*regexp.Regexp {
+	patterns := map[string]*regexp.Regexp{}
+	for name := range tags {
+		patterns[name] = regexp.MustCompile(`\{%-?\s*end` + name + `\s*-?%\}`)
+	}
+	return patterns
+}
+
+// liquidTagName returns the name of the tag from the markup between {% and %},
+// ignoring whitespace control characters.
+func liquidTagName(markup string) string {
+	markup = strings.TrimSpace(strings.Trim(markup, "-"))
+	if strings.HasPrefix(markup, "#") {
+		return "#"
+	}
+	if fields := strings.Fields(markup); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+func liquidError(key string, line int, msg string, args ...interface{}) error {
+	return fmt.Errorf("invalid liquid in %s at line %v: %s", key, line, fmt.Sprintf(msg, args...))
+}
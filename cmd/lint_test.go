@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Shopify/themekit/src/file"
+	"github.com/Shopify/themekit/src/shopify"
+)
+
+func TestLintLiquid(t *testing.T) {
+	testcases := []struct {
+		value, err, warning string
+	}{
+		{value: "<h1>{{ product.title }}</h1>\n{% if product %}\n{%- for v in product.variants -%}{{ v }}{% endfor %}\n{% else %}none{% endif %}"},
+		{value: "{% comment %}{% if %}{% endcomment %}{% raw %}{{ not liquid {% endraw %}"},
+		{value: "{% schema %}\n{\"name\": \"{% for\"}\n{% endschema %}"},
+		{value: "{% # a comment %}<style>a{color:red}</style>"},
+		{value: "<p>\n{{ product.title </p>", err: "invalid liquid in templates/index.liquid at line 2: {{ is never terminated with }}"},
+		{value: "{% if a %}\n{% endfor %}", err: "at line 2: unexpected endfor, expected endif for the if on line 1"},
+		{value: "\n{% if a %}", err: "at line 2: if is never closed with endif"},
+		{value: "{% endif %}", err: "at line 1: unexpected endif"},
+		{value: "{% fi a %}", warning: "unknown liquid tag fi in templates/index.liquid at line 1"},
+		{value: "\n\n{% endfi %}", warning: "unknown liquid tag endfi in templates/index.liquid at line 3"},
+		{value: "{% for p in products %}{% ifchanged %}{{ p.type }}{% endifchanged %}{% endfor %}"},
+		{value: "\n{% fi %}\n{% if a %}", warning: "at line 2", err: "at line 3: if is never closed with endif"},
+		{value: "{% raw %}{{", err: "at line 1: raw is never closed with endraw"},
+	}
+
+	for _, testcase := range testcases {
+		warnings, err := lintLiquid(shopify.Asset{Key: "templates/index.liquid", Value: testcase.value})
+		if testcase.warning == "" {
+			assert.Empty(t, warnings, testcase.value)
+		} else if assert.Len(t, warnings, 1, testcase.value) {
+			assert.Contains(t, warnings[0], testcase.warning)
+		}
+		if testcase.err == "" {
+			assert.Nil(t, err, testcase.value)
+		} else if assert.NotNil(t, err, testcase.value) {
+			assert.Contains(t, err.Error(), testcase.err)
+		}
+	}
+}
+
+func TestPerformLint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "themekit-lint")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "templates"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "templates", "index.liquid"), []byte("{% if a %}"), 0644))
+
+	ctx, m, _, _, se := createTestCtx()
+	ctx.Env.Directory = dir
+	ctx.Flags.Lint = true
+	perform(context.Background(), ctx, "templates/index.liquid", file.Update, "")
+	assert.Contains(t, se.String(), "if is never closed with endif")
+	m.AssertExpectations(t)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "templates", "index.liquid"), []byte("{% fi %}"), 0644))
+	ctx, m, _, so, _ := createTestCtx()
+	ctx.Env.Directory = dir
+	ctx.Flags.Lint = true
+	m.On("UpdateAsset", mock.Anything, mock.MatchedBy(func(a shopify.Asset) bool { return a.Value == "{% fi %}" }), "").Return(nil)
+	perform(context.Background(), ctx, "templates/index.liquid", file.Update, "")
+	assert.Contains(t, so.String(), "unknown liquid tag fi")
+	m.AssertExpectations(t)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Shopify/themekit/src/cmdutil"
+	"github.com/Shopify/themekit/src/colors"
+	"github.com/Shopify/themekit/src/shopify"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list <filenames>",
+	Short: "List the theme files on shopify",
+	Long: `List will print the files on shopify along with when they were last updated,
+ without downloading them. If provided with file names, directories or glob patterns
+ then only the matching files will be listed.
+ `,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// listing does not change the theme so it should not care about the live theme
+		flags.AllowLive = true
+		return cmdutil.ForEachClient(flags, args, list)
+	},
+}
+
+// listedAsset is the output of a single remote file when the --json flag is set
+type listedAsset struct {
+	Key         string `json:"key"`
+	UpdatedAt   string `json:"updated_at"`
+	Environment string `json:"environment"`
+}
+
+func list(ctx *cmdutil.Ctx) error {
+	ctx.DisableSummary()
+
+	if ctx.Flags.Sort != "" && ctx.Flags.Sort != "name" && ctx.Flags.Sort != "date" {
+		return fmt.Errorf("[%s] invalid sort %v, must be either name or date", colors.Green(ctx.Env.Name), ctx.Flags.Sort)
+	}
+
+	remoteAssets, err := ctx.Client.GetAllAssets()
+	if err != nil {
+		return err
+	}
+
+	assets := []shopify.Asset{}
+	for _, asset := range remoteAssets {
+		if len(ctx.Args) == 0 || matchesArgs(asset.Key, ctx.Args) {
+			assets = append(assets, asset)
+		}
+	}
+
+	sortAssets(assets, ctx.Flags.Sort)
+
+	for _, asset := range assets {
+		if ctx.Flags.JSON {
+			out, _ := json.Marshal(listedAsset{Key: asset.Key, UpdatedAt: asset.UpdatedAt, Environment: ctx.Env.Name})
+			fmt.Fprintln(ctx.Out, string(out))
+		} else {
+			fmt.Fprintf(ctx.Out, "[%s] %s %s\n", colors.Green(ctx.Env.Name), colors.Blue(asset.Key), asset.UpdatedAt)
+		}
+	}
+
+	return nil
+}
+
+// sortAssets sorts by key, or with the most recently updated first when sorting by date
+func sortAssets(assets []shopify.Asset, by string) {
+	sort.SliceStable(assets, func(i, j int) bool {
+		if by == "date" {
+			left, _ := time.Parse(time.RFC3339, assets[i].UpdatedAt)
+			right, _ := time.Parse(time.RFC3339, assets[j].UpdatedAt)
+			if !left.Equal(right) {
+				return left.After(right)
+			}
+		}
+		return assets[i].Key < assets[j].Key
+	})
+}
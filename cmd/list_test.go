@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Shopify/themekit/src/shopify"
+)
+
+func TestList(t *testing.T) {
+	remoteAssets := []shopify.Asset{
+		{Key: "assets/app.js", UpdatedAt: "2020-01-02T10:00:00-05:00"},
+		{Key: "layout/theme.liquid", UpdatedAt: "2020-03-01T10:00:00-05:00"},
+		{Key: "templates/index.liquid", UpdatedAt: "2020-02-01T10:00:00-05:00"},
+	}
+
+	ctx, client, _, stdOut, _ := createTestCtx()
+	client.On("GetAllAssets").Return(remoteAssets, nil)
+	assert.Nil(t, list(ctx))
+	assert.Equal(t, "[] assets/app.js 2020-01-02T10:00:00-05:00\n[] layout/theme.liquid 2020-03-01T10:00:00-05:00\n[] templates/index.liquid 2020-02-01T10:00:00-05:00\n", stdOut.String())
+
+	ctx, client, _, stdOut, _ = createTestCtx()
+	ctx.Flags.Quiet = true
+	ctx.Log = log.New(ioutil.Discard, "", 0)
+	ctx.Flags.Sort = "date"
+	ctx.Args = []string{"layout", "templates/*"}
+	client.On("GetAllAssets").Return(remoteAssets, nil)
+	assert.Nil(t, list(ctx))
+	assert.Equal(t, "[] layout/theme.liquid 2020-03-01T10:00:00-05:00\n[] templates/index.liquid 2020-02-01T10:00:00-05:00\n", stdOut.String())
+
+	ctx, client, _, _, _ = createTestCtx()
+	out := bytes.NewBufferString("")
+	ctx.Out = out
+	ctx.Flags.JSON = true
+	ctx.Env.Name = "development"
+	ctx.Args = []string{"assets/app.js"}
+	client.On("GetAllAssets").Return(remoteAssets, nil)
+	assert.Nil(t, list(ctx))
+	assert.Equal(t, `{"key":"assets/app.js","updated_at":"2020-01-02T10:00:00-05:00","environment":"development"}`+"\n", out.String())
+
+	ctx, _, _, _, _ = createTestCtx()
+	ctx.Flags.Sort = "size"
+	err := list(ctx)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "invalid sort size")
+	}
+
+	ctx, client, _, _, _ = createTestCtx()
+	client.On("GetAllAssets").Return([]shopify.Asset{}, fmt.Errorf("server error"))
+	assert.EqualError(t, list(ctx), "server error")
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"io/ioutil"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -24,15 +26,21 @@ var newCmd = &cobra.Command{
   For more information, refer to https://shopify.dev/tools/theme-kit/command-reference#new.
   `,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// This is a hack to get around theme ID validation for the list operation which doesn't need it
-		flags.ThemeID = "1337"
-		return cmdutil.ForDefaultClient(flags, args, func(ctx *cmdutil.Ctx) error {
+		return cmdutil.ForDefaultClient(withoutTheme(flags), args, func(ctx *cmdutil.Ctx) error {
 			return newTheme(ctx, static.Unbundle)
 		})
 	},
 }
 
 func newTheme(ctx *cmdutil.Ctx, generate func(ctx *cmdutil.Ctx) error) error {
+	if !ctx.Flags.Force {
+		if empty, err := isEmptyDir(ctx.Env.Directory); err != nil {
+			return err
+		} else if !empty {
+			return fmt.Errorf("%s is not empty, use the --force flag to generate the theme there anyway", ctx.Env.Directory)
+		}
+	}
+
 	theme, err := ctx.Client.CreateNewTheme(ctx.Flags.Name)
 	if err != nil {
 		if err == shopify.ErrThemeNameRequired {
@@ -55,3 +63,19 @@ func newTheme(ctx *cmdutil.Ctx, generate func(ctx *cmdutil.Ctx) error) error {
 	ctx.Log.Printf("[%s] uploading new files to shopify", colors.Yellow(ctx.Env.Domain))
 	return deploy(ctx)
 }
+
+// isEmptyDir will return true if the directory has no files in it. Hidden files
+// like .git are not counted.
+func isEmptyDir(dir string) (bool, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+
+	for _, info := range files {
+		if !strings.HasPrefix(info.Name(), ".") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -3,6 +3,9 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -14,18 +17,23 @@ import (
 
 func TestNewTheme(t *testing.T) {
 	name := "name"
+	dir, err := ioutil.TempDir("", "themekit-new")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
 
 	ctx, client, conf, _, _ := createTestCtx()
 	ctx.Flags.Name = name
+	ctx.Env.Directory = dir
 	client.On("CreateNewTheme", name).Return(shopify.Theme{ID: 42}, nil)
-	conf.On("Set", "development", env.Env{ThemeID: "42"}).Return(nil, nil)
+	conf.On("Set", "development", env.Env{ThemeID: "42", Directory: dir}).Return(nil, nil)
 	conf.On("Save").Return(nil)
 	client.On("GetAllAssets").Return([]shopify.Asset{}, nil)
-	err := newTheme(ctx, func(ctx *cmdutil.Ctx) error { return nil })
-	assert.Error(t, err)
+	err = newTheme(ctx, func(ctx *cmdutil.Ctx) error { return nil })
+	assert.Nil(t, err)
 
 	ctx, client, _, _, _ = createTestCtx()
 	ctx.Flags.Name = name
+	ctx.Env.Directory = dir
 	client.On("CreateNewTheme", name).Return(shopify.Theme{}, fmt.Errorf("can't create theme"))
 	err = newTheme(ctx, func(ctx *cmdutil.Ctx) error { return nil })
 	if assert.NotNil(t, err) {
@@ -34,8 +42,9 @@ func TestNewTheme(t *testing.T) {
 
 	ctx, client, conf, _, _ = createTestCtx()
 	ctx.Flags.Name = name
+	ctx.Env.Directory = dir
 	client.On("CreateNewTheme", name).Return(shopify.Theme{ID: 44}, nil)
-	conf.On("Set", "development", env.Env{ThemeID: "44"}).Return(nil, fmt.Errorf("cant set config"))
+	conf.On("Set", "development", env.Env{ThemeID: "44", Directory: dir}).Return(nil, fmt.Errorf("cant set config"))
 	err = newTheme(ctx, func(ctx *cmdutil.Ctx) error { return nil })
 	if assert.NotNil(t, err) {
 		assert.Contains(t, err.Error(), "cant set config")
@@ -43,11 +52,53 @@ func TestNewTheme(t *testing.T) {
 
 	ctx, client, conf, _, _ = createTestCtx()
 	ctx.Flags.Name = name
+	ctx.Env.Directory = dir
 	client.On("CreateNewTheme", name).Return(shopify.Theme{ID: 48}, nil)
-	conf.On("Set", "development", env.Env{ThemeID: "48"}).Return(nil, nil)
+	conf.On("Set", "development", env.Env{ThemeID: "48", Directory: dir}).Return(nil, nil)
 	conf.On("Save").Return(nil)
 	err = newTheme(ctx, func(ctx *cmdutil.Ctx) error { return errors.New("oh no") })
 	if assert.NotNil(t, err) {
 		assert.Contains(t, err.Error(), "oh no")
 	}
+
+	ctx, client, _, _, _ = createTestCtx()
+	ctx.Flags.Name = name
+	ctx.Env.Directory = "_testdata"
+	err = newTheme(ctx, func(ctx *cmdutil.Ctx) error { return nil })
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "use the --force flag")
+	}
+	client.AssertNotCalled(t, "CreateNewTheme", name)
+
+	ctx, client, conf, _, _ = createTestCtx()
+	ctx.Flags.Name = name
+	ctx.Flags.Force = true
+	ctx.Env.Directory = "_testdata"
+	client.On("CreateNewTheme", name).Return(shopify.Theme{ID: 50}, nil)
+	conf.On("Set", "development", env.Env{ThemeID: "50", Directory: "_testdata"}).Return(nil, fmt.Errorf("cant set config"))
+	err = newTheme(ctx, func(ctx *cmdutil.Ctx) error { return nil })
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "cant set config")
+	}
+}
+
+func TestIsEmptyDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "themekit-new")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	empty, err := isEmptyDir(dir)
+	assert.Nil(t, err)
+	assert.True(t, empty)
+
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, ".git"), 0755))
+	empty, _ = isEmptyDir(dir)
+	assert.True(t, empty)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "config.yml"), []byte(""), 0644))
+	empty, _ = isEmptyDir(dir)
+	assert.False(t, empty)
+
+	_, err = isEmptyDir(filepath.Join(dir, "nope"))
+	assert.NotNil(t, err)
 }
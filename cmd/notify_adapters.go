@@ -6,14 +6,24 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/Shopify/themekit/src/cmdutil"
 	"github.com/Shopify/themekit/src/colors"
+	"github.com/Shopify/themekit/src/file"
 )
 
 type notifyAdapter interface {
-	notify(*cmdutil.Ctx, string)
+	notify(ctx *cmdutil.Ctx, event string, files []string)
+}
+
+// notification is the payload posted to a notify url
+type notification struct {
+	Environment string   `json:"environment"`
+	Event       string   `json:"event"`
+	Files       []string `json:"files"`
+	Timestamp   string   `json:"timestamp"`
 }
 
 func newNotifyAdapter(notifyPath string) notifyAdapter {
@@ -32,15 +42,20 @@ func newNotifyAdapter(notifyPath string) notifyAdapter {
 
 type noopNotify struct{}
 
-func (noop *noopNotify) notify(*cmdutil.Ctx, string) {}
+func (noop *noopNotify) notify(*cmdutil.Ctx, string, []string) {}
 
 type urlNotify struct {
 	url    string
 	client http.Client
 }
 
-func (urlNote *urlNotify) notify(ctx *cmdutil.Ctx, path string) {
-	body, _ := json.Marshal(map[string]interface{}{"files": []string{path}})
+func (urlNote *urlNotify) notify(ctx *cmdutil.Ctx, event string, files []string) {
+	body, _ := json.Marshal(notification{
+		Environment: ctx.Env.Name,
+		Event:       event,
+		Files:       files,
+		Timestamp:   time.Now().Format(time.RFC3339),
+	})
 	resp, err := urlNote.client.Post(urlNote.url, "application/json", bytes.NewBuffer(body))
 	if err != nil {
 		ctx.Log.Printf(
@@ -51,6 +66,14 @@ func (urlNote *urlNotify) notify(ctx *cmdutil.Ctx, path string) {
 		)
 	} else {
 		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			ctx.Log.Printf(
+				`[%s] Error while notifying webhook "%s": server responded with %s`,
+				colors.Green(ctx.Env.Name),
+				colors.Blue(ctx.Env.Notify),
+				resp.Status,
+			)
+		}
 	}
 }
 
@@ -58,7 +81,37 @@ type fileNotify struct {
 	path string
 }
 
-func (fileNote *fileNotify) notify(*cmdutil.Ctx, string) {
-	os.Create(fileNote.path)
+func (fileNote *fileNotify) notify(ctx *cmdutil.Ctx, event string, files []string) {
+	f, err := os.OpenFile(fileNote.path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		ctx.Log.Printf(
+			`[%s] Error while notifying file "%s": %s`,
+			colors.Green(ctx.Env.Name),
+			colors.Blue(fileNote.path),
+			err,
+		)
+		return
+	}
+	f.Close()
 	os.Chtimes(fileNote.path, time.Now(), time.Now())
 }
+
+// notifyChanges will notify the environment's notify target of the files that were
+// changed by a successful run. Nothing is sent if the run had errors or changed nothing.
+func notifyChanges(ctx *cmdutil.Ctx, notifier notifyAdapter, event string, actions map[string]file.Op) {
+	if ctx.HasErrors() {
+		return
+	}
+
+	files := []string{}
+	for path, op := range actions {
+		if op == file.Update || op == file.Remove {
+			files = append(files, path)
+		}
+	}
+
+	if len(files) > 0 {
+		sort.Strings(files)
+		notifier.notify(ctx, event, files)
+	}
+}
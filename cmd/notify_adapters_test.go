@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/Shopify/themekit/src/file"
 )
 
 func TestNewNotifyAdapter(t *testing.T) {
@@ -30,7 +32,7 @@ func TestNewNotifyAdapter(t *testing.T) {
 func TestNoopAdapter(t *testing.T) {
 	adapter := newNotifyAdapter("")
 	ctx, _, _, _, _ := createTestCtx()
-	adapter.notify(ctx, "")
+	adapter.notify(ctx, "update", []string{})
 }
 
 func TestNotifyFile(t *testing.T) {
@@ -42,7 +44,7 @@ func TestNotifyFile(t *testing.T) {
 	_, err := os.Stat(notifyPath)
 	assert.True(t, os.IsNotExist(err))
 
-	adapter.notify(ctx, "")
+	adapter.notify(ctx, "update", []string{})
 	_, err = os.Stat(notifyPath)
 	assert.Nil(t, err)
 	// need to make the time different larger than milliseconds because windows
@@ -51,7 +53,7 @@ func TestNotifyFile(t *testing.T) {
 	info1, err := os.Stat(notifyPath)
 	assert.Nil(t, err)
 
-	adapter.notify(ctx, "")
+	adapter.notify(ctx, "update", []string{})
 	info2, err := os.Stat(notifyPath)
 	assert.Nil(t, err)
 	assert.NotEqual(t, info1.ModTime(), info2.ModTime())
@@ -68,11 +70,48 @@ func TestNotifyURL(t *testing.T) {
 		files, _ := data["files"].([]interface{})
 		assert.Equal(t, 1, len(files))
 		assert.Equal(t, "assets/app.js", files[0])
+		assert.Equal(t, "development", data["environment"])
+		assert.Equal(t, "deploy", data["event"])
+		assert.NotEmpty(t, data["timestamp"])
 	}))
 	defer server.Close()
 
 	ctx, _, _, _, _ := createTestCtx()
+	ctx.Env.Name = "development"
 
 	adapter := newNotifyAdapter(server.URL)
-	adapter.notify(ctx, "assets/app.js")
+	adapter.notify(ctx, "deploy", []string{"assets/app.js"})
+}
+
+func TestNotifyURLFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	ctx, _, _, stdOut, _ := createTestCtx()
+	ctx.Env.Notify = server.URL
+	newNotifyAdapter(server.URL).notify(ctx, "deploy", []string{"assets/app.js"})
+	assert.Contains(t, stdOut.String(), "server responded with 500")
+}
+
+func TestNotifyChanges(t *testing.T) {
+	actions := map[string]file.Op{"assets/b.js": file.Update, "assets/a.js": file.Remove, "assets/c.js": file.Skip}
+
+	ctx, _, _, _, _ := createTestCtx()
+	notifier := new(testAdapter)
+	notifier.On("notify", ctx, "deploy", []string{"assets/a.js", "assets/b.js"})
+	notifyChanges(ctx, notifier, "deploy", actions)
+	notifier.AssertExpectations(t)
+
+	ctx, _, _, _, _ = createTestCtx()
+	notifier = new(testAdapter)
+	notifyChanges(ctx, notifier, "deploy", map[string]file.Op{"assets/c.js": file.Skip})
+	notifier.AssertNotCalled(t, "notify", ctx, "deploy", []string{})
+
+	ctx, _, _, _, _ = createTestCtx()
+	ctx.Err("something failed")
+	notifier = new(testAdapter)
+	notifyChanges(ctx, notifier, "deploy", actions)
+	notifier.AssertNotCalled(t, "notify", ctx, "deploy", []string{"assets/a.js", "assets/b.js"})
 }
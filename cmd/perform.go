@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+
+	"github.com/Shopify/themekit/src/cmdutil"
+	"github.com/Shopify/themekit/src/colors"
+	"github.com/Shopify/themekit/src/file"
+	"github.com/Shopify/themekit/src/shopify"
+)
+
+// defaultWorkers is the amount of files that are transferred at the same time
+// when the --workers flag is not set.
+const defaultWorkers = 4
+
+// perform will run a single file operation, the requests it makes are aborted
+// when reqCtx is cancelled. The client reports the result of every request to
+// the context, perform only reports the operations that never made a request.
+func perform(reqCtx context.Context, ctx *cmdutil.Ctx, path string, op file.Op, checksum string) {
+	path = shopify.NormalizeKey(path)
+	reqCtx = shopify.WithResults(reqCtx, ctx.Result)
+	var err error
+	requested := false
+	defer func() {
+		ctx.DoneTask(op)
+		if !requested {
+			ctx.Result(shopify.AssetEvent{Event: op, Asset: shopify.Asset{Key: path}, Err: err})
+		}
+	}()
+
+	switch op {
+	case file.Skip:
+		if ctx.Flags.Verbose {
+			localAsset, _ := shopify.ReadAsset(ctx.Env, path)
+			checksumOutput := "Checksum: " + localAsset.Checksum
+			ctx.Log.Printf("[%s] %s %s (%s)", colors.Green(ctx.Env.Name), colors.Cyan("Skipped"), colors.Blue(path), checksumOutput)
+		}
+	case file.Remove:
+		// a file that is already gone, like when a delete is retried, counts as deleted
+		requested = true
+		if err = ctx.Client.DeleteAsset(reqCtx, shopify.Asset{Key: path}); err == shopify.ErrNotPartOfTheme {
+			err = nil
+			if ctx.Flags.Verbose {
+				ctx.Log.Printf("[%s] %s was already deleted", colors.Green(ctx.Env.Name), colors.Blue(path))
+			}
+		} else if err != nil {
+			ctx.Err("[%s] (%s) %s", colors.Green(ctx.Env.Name), colors.Blue(path), err)
+		} else if ctx.Flags.Verbose {
+			ctx.Log.Printf("[%s] Deleted %s", colors.Green(ctx.Env.Name), colors.Blue(path))
+		}
+	case file.Get:
+		var asset shopify.Asset
+		requested = true
+		if asset, err = ctx.Client.GetAsset(reqCtx, path); err != nil {
+			ctx.Err("[%s] error downloading %s: %s", colors.Green(ctx.Env.Name), colors.Blue(path), err)
+		} else if err = asset.Write(ctx.Env.Directory); err != nil {
+			// the download succeeded so the failure to write it is reported as well
+			requested = false
+			ctx.Err("[%s] error writing %s: %s", colors.Green(ctx.Env.Name), colors.Blue(asset.Key), err)
+		} else if err = preserveModTime(ctx, asset); err != nil {
+			requested = false
+			ctx.Err("[%s] error setting the modification time of %s: %s", colors.Green(ctx.Env.Name), colors.Blue(asset.Key), err)
+		} else {
+			ctx.AddBytes(asset.Size())
+			if ctx.Flags.Verbose {
+				ctx.Log.Printf("[%s] Successfully wrote %s to disk", colors.Green(ctx.Env.Name), colors.Blue(asset.Key))
+			}
+		}
+	default:
+		var asset shopify.Asset
+		asset, err = shopify.ReadAsset(ctx.Env, path)
+		if err != nil {
+			ctx.Err("[%s] error loading %s: %s", colors.Green(ctx.Env.Name), colors.Green(path), colors.Red(err))
+			return
+		} else if asset.Ignored() {
+			op = file.Skip
+			ctx.Log.Printf("[%s] %s %s (themekit:ignore)", colors.Green(ctx.Env.Name), colors.Cyan("Skipped"), colors.Blue(path))
+			return
+		} else if asset.OverMaxFileSize(ctx.Env) {
+			op = file.Skip
+			ctx.Log.Printf("[%s] %s %s (larger than max_file_size)", colors.Green(ctx.Env.Name), colors.Yellow("Skipped"), colors.Blue(path))
+			return
+		} else if asset, err = shopify.TransformAsset(ctx.Env, asset); err != nil {
+			ctx.Err("[%s] %s", colors.Green(ctx.Env.Name), err)
+			return
+		} else if err = asset.Validate(); err != nil {
+			ctx.Err("[%s] %s", colors.Green(ctx.Env.Name), err)
+			return
+		} else if path == settingsDataKey {
+			if err = validateSettingsData(asset); err != nil {
+				ctx.Err("[%s] %s", colors.Green(ctx.Env.Name), err)
+				return
+			}
+		} else if ctx.Flags.Lint && strings.HasSuffix(path, ".liquid") {
+			var warnings []string
+			warnings, err = lintLiquid(asset)
+			for _, warning := range warnings {
+				ctx.Log.Printf("[%s] %s %s", colors.Green(ctx.Env.Name), colors.Yellow("warn"), warning)
+			}
+			if err != nil {
+				ctx.Err("[%s] %s", colors.Green(ctx.Env.Name), err)
+				return
+			}
+		}
+
+		requested = true
+		if err = ctx.Client.UpdateAsset(reqCtx, asset, checksum); err != nil {
+			ctx.Err("[%s] (%s) %s", colors.Green(ctx.Env.Name), colors.Blue(asset.Key), err)
+		} else {
+			ctx.AddBytes(asset.Size())
+			if ctx.Flags.Verbose {
+				ctx.Log.Printf("[%s] Updated %s", colors.Green(ctx.Env.Name), colors.Blue(asset.Key))
+			}
+		}
+	}
+}
+
+// preserveModTime sets the modification time of a downloaded asset to when it was
+// updated on shopify unless it was disabled with --no-preserve-mtime.
+func preserveModTime(ctx *cmdutil.Ctx, asset shopify.Asset) error {
+	if ctx.Flags.NoPreserveModTime {
+		return nil
+	}
+	return asset.PreserveModTime(ctx.Env.Directory)
+}
+
+// performAll will perform every action with a bounded pool of workers so that
+// large themes do not open a connection for every file at once. The settings
+// data is always performed last so that it is only changed once all the files
+// that it references are in place. An interrupt stops any new actions from
+// starting and aborts the requests that are in flight, a second interrupt kills
+// the process as usual.
+func performAll(ctx *cmdutil.Ctx, actions map[string]file.Op) {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	performAllUntil(ctx, actions, interrupt)
+}
+
+func performAllUntil(ctx *cmdutil.Ctx, actions map[string]file.Op, interrupt chan os.Signal) {
+	workers := ctx.Flags.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+
+	reqCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-interrupt:
+			// stop catching interrupts so that the next one is not ignored
+			signal.Stop(interrupt)
+			cancel()
+		case <-done:
+		}
+	}()
+
+	var workerGroup sync.WaitGroup
+	jobs := make(chan string)
+	for i := 0; i < workers; i++ {
+		workerGroup.Add(1)
+		go func() {
+			defer workerGroup.Done()
+			for path := range jobs {
+				perform(reqCtx, ctx, path, actions[path], "")
+			}
+		}()
+	}
+
+	remaining := len(actions)
+	for path := range actions {
+		if path == settingsDataKey {
+			continue
+		} else if reqCtx.Err() != nil {
+			break
+		}
+		select {
+		case jobs <- path:
+			remaining--
+		case <-reqCtx.Done():
+		}
+	}
+	close(jobs)
+	workerGroup.Wait()
+
+	if reqCtx.Err() != nil {
+		ctx.Err("[%s] cancelled, %v files remaining", colors.Green(ctx.Env.Name), remaining)
+	} else if op, ok := actions[settingsDataKey]; ok {
+		perform(reqCtx, ctx, settingsDataKey, op, "")
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Shopify/themekit/src/file"
+	"github.com/Shopify/themekit/src/shopify"
+)
+
+func TestPerform(t *testing.T) {
+	key := "assets/app.js"
+
+	ctx, m, _, _, se := createTestCtx()
+	perform(context.Background(), ctx, "bad", file.Update, "")
+	assert.Contains(t, se.String(), "readAsset: ")
+	m.AssertExpectations(t)
+
+	ctx, m, _, _, se = createTestCtx()
+	ctx.Env.Directory = "_testdata/projectdir"
+	m.On("UpdateAsset", mock.Anything, shopify.Asset{Key: key, Checksum: "d41d8cd98f00b204e9800998ecf8427e"}, "").Return(fmt.Errorf("shopify says no update"), "")
+	perform(context.Background(), ctx, key, file.Update, "")
+	assert.Contains(t, se.String(), "shopify says no update")
+	m.AssertExpectations(t)
+
+	ctx, m, _, so, _ := createTestCtx()
+	ctx.Env.Directory = "_testdata/projectdir"
+	m.On("UpdateAsset", mock.Anything, shopify.Asset{Key: key, Checksum: "d41d8cd98f00b204e9800998ecf8427e"}, "").Return(nil)
+	perform(context.Background(), ctx, key, file.Update, "")
+	assert.NotContains(t, so.String(), "Updated")
+	m.AssertExpectations(t)
+
+	ctx, m, _, so, _ = createTestCtx()
+	ctx.Env.Directory = "_testdata/projectdir"
+	ctx.Flags.Verbose = true
+	m.On("UpdateAsset", mock.Anything, shopify.Asset{Key: key, Checksum: "d41d8cd98f00b204e9800998ecf8427e"}, "").Return(nil)
+	perform(context.Background(), ctx, key, file.Update, "")
+	assert.Contains(t, so.String(), "Updated")
+	m.AssertExpectations(t)
+
+	ctx, m, _, so, se = createTestCtx()
+	m.On("DeleteAsset", mock.Anything, mock.MatchedBy(func(a shopify.Asset) bool { return a.Key == "good" })).Return(nil)
+	m.On("DeleteAsset", mock.Anything, mock.MatchedBy(func(a shopify.Asset) bool { return a.Key == "bad" })).Return(fmt.Errorf("shopify says no update"))
+
+	perform(context.Background(), ctx, "bad", file.Remove, "")
+	assert.Contains(t, se.String(), "shopify says no update")
+
+	perform(context.Background(), ctx, "good", file.Remove, "")
+	assert.NotContains(t, so.String(), "Deleted")
+
+	ctx.Flags.Verbose = true
+	perform(context.Background(), ctx, "good", file.Remove, "")
+	assert.Contains(t, so.String(), "Deleted")
+
+	m.AssertExpectations(t)
+
+	ctx, m, _, so, se = createTestCtx()
+	ctx.Flags.Verbose = true
+	m.On("DeleteAsset", mock.Anything, shopify.Asset{Key: "gone"}).Return(shopify.ErrNotPartOfTheme)
+	perform(context.Background(), ctx, "gone", file.Remove, "")
+	assert.Equal(t, "", se.String())
+	assert.Contains(t, so.String(), "gone was already deleted")
+	assert.False(t, ctx.HasErrors())
+	m.AssertExpectations(t)
+
+	ctx, m, _, _, _ = createTestCtx()
+	out := bytes.NewBufferString("")
+	ctx.Out = out
+	ctx.Flags.JSON = true
+	perform(context.Background(), ctx, "bad", file.Update, "")
+	assert.Contains(t, out.String(), `"file":"bad"`)
+	assert.Contains(t, out.String(), `"status":"error"`)
+	assert.Contains(t, out.String(), `"error":"readAsset: `)
+
+	// the client reports the results of the requests it makes
+	out.Reset()
+	m.On("DeleteAsset", mock.Anything, shopify.Asset{Key: "bad"}).Return(fmt.Errorf("shopify says no update"))
+	perform(context.Background(), ctx, "bad", file.Remove, "")
+	assert.Equal(t, "", out.String())
+	m.AssertExpectations(t)
+}
+
+func TestPerformAll(t *testing.T) {
+	ctx, client, _, _, _ := createTestCtx()
+	ctx.Flags.Workers = 2
+
+	var mu sync.Mutex
+	var running, maxRunning int
+	var order []string
+	client.On("DeleteAsset", mock.Anything, mock.MatchedBy(func(shopify.Asset) bool { return true })).Run(func(args mock.Arguments) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		order = append(order, args.Get(1).(shopify.Asset).Key)
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+	}).Return(nil)
+
+	actions := map[string]file.Op{settingsDataKey: file.Remove}
+	for i := 0; i < 10; i++ {
+		actions[fmt.Sprintf("assets/%v.js", i)] = file.Remove
+	}
+	performAll(ctx, actions)
+
+	assert.Equal(t, 2, maxRunning)
+	assert.Equal(t, 11, len(order))
+	assert.Equal(t, settingsDataKey, order[len(order)-1])
+}
+
+func TestPerformAllInterrupted(t *testing.T) {
+	ctx, client, _, _, stdErr := createTestCtx()
+	ctx.Flags.Workers = 1
+
+	interrupt := make(chan os.Signal, 1)
+	client.On("DeleteAsset", mock.Anything, mock.MatchedBy(func(shopify.Asset) bool { return true })).Run(func(args mock.Arguments) {
+		interrupt <- os.Interrupt
+		// the request that is in flight is aborted
+		<-args.Get(0).(context.Context).Done()
+	}).Return(context.Canceled).Once()
+
+	actions := map[string]file.Op{settingsDataKey: file.Remove}
+	for i := 0; i < 10; i++ {
+		actions[fmt.Sprintf("assets/%v.js", i)] = file.Remove
+	}
+	performAllUntil(ctx, actions, interrupt)
+
+	client.AssertNumberOfCalls(t, "DeleteAsset", 1)
+	assert.Contains(t, stdErr.String(), "context canceled")
+	assert.Contains(t, stdErr.String(), "cancelled, 10 files remaining")
+	assert.True(t, ctx.HasErrors())
+}
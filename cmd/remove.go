@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"github.com/Shopify/themekit/src/cmdutil"
 	"github.com/Shopify/themekit/src/colors"
 	"github.com/Shopify/themekit/src/file"
+	"github.com/Shopify/themekit/src/shopify"
 )
 
 var removeCmd = &cobra.Command{
@@ -30,21 +32,29 @@ var removeCmd = &cobra.Command{
 func remove(ctx *cmdutil.Ctx, removeFile func(string) error) error {
 	if ctx.Env.ReadOnly {
 		return fmt.Errorf("[%s] environment is readonly", colors.Green(ctx.Env.Name))
+	} else if len(ctx.Args) == 0 && !ctx.Flags.AllowEmpty {
+		return fmt.Errorf("[%s] please specify file(s) to be removed, or pass --allow-empty", colors.Green(ctx.Env.Name))
 	} else if len(ctx.Args) == 0 {
-		return fmt.Errorf("[%s] please specify file(s) to be removed", colors.Green(ctx.Env.Name))
+		return nil
 	}
 
 	var removeGroup sync.WaitGroup
 	ctx.StartProgress(len(ctx.Args))
+	removed := map[string]file.Op{}
 	for _, filename := range ctx.Args {
+		key := shopify.NormalizeKey(filename)
+		removed[key] = file.Remove
 		removeGroup.Add(1)
-		go func(filename string) {
+		go func(key string) {
 			defer removeGroup.Done()
-			perform(ctx, filename, file.Remove, "")
-			removeFile(filepath.Join(ctx.Env.Directory, filename))
-		}(filename)
+			perform(context.Background(), ctx, key, file.Remove, "")
+			removeFile(filepath.Join(ctx.Env.Directory, filepath.FromSlash(key)))
+		}(key)
 	}
 
 	removeGroup.Wait()
+
+	notifyChanges(ctx, newNotifyAdapter(ctx.Env.Notify), "remove", removed)
+
 	return nil
 }
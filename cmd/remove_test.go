@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
 	"github.com/Shopify/themekit/src/cmdutil"
 	"github.com/Shopify/themekit/src/cmdutil/_mocks"
@@ -16,12 +17,15 @@ import (
 
 func TestRemove(t *testing.T) {
 	testcases := []struct {
-		args, err string
-		readonly  bool
+		args, key, err       string
+		readonly, allowEmpty bool
 	}{
-		{args: filepath.Join("templates", "layout.liquid")},
+		{args: filepath.Join("templates", "layout.liquid"), key: "templates/layout.liquid"},
+		{args: "/templates/layout.liquid", key: "templates/layout.liquid"},
+		{args: `templates\layout.liquid`, key: "templates/layout.liquid"},
 		{args: filepath.Join("templates", "layout.liquid"), readonly: true, err: "environment is readonly"},
 		{err: "please specify file(s) to be removed"},
+		{allowEmpty: true},
 	}
 
 	for _, testcase := range testcases {
@@ -30,11 +34,12 @@ func TestRemove(t *testing.T) {
 			ctx.Args = []string{testcase.args}
 		}
 		ctx.Env.ReadOnly = testcase.readonly
+		ctx.Flags.AllowEmpty = testcase.allowEmpty
 
-		client.On("DeleteAsset", shopify.Asset{Key: testcase.args}).Return(nil)
+		client.On("DeleteAsset", mock.Anything, shopify.Asset{Key: testcase.key}).Return(nil)
 
 		err := remove(ctx, func(path string) error {
-			assert.Equal(t, path, testcase.args)
+			assert.Equal(t, filepath.FromSlash(testcase.key), path)
 			return nil
 		})
 
@@ -43,6 +48,9 @@ func TestRemove(t *testing.T) {
 		} else if assert.NotNil(t, err, testcase.err) {
 			assert.Contains(t, err.Error(), testcase.err)
 		}
+		if testcase.allowEmpty {
+			client.AssertNotCalled(t, "DeleteAsset", mock.Anything, mock.Anything)
+		}
 	}
 }
 
@@ -57,8 +65,10 @@ func createTestCtx() (ctx *cmdutil.Ctx, client *mocks.ShopifyClient, conf *mocks
 		Flags: cmdutil.Flags{
 			Environments: []string{"development"},
 		},
-		Log:    log.New(stdOut, "", 0),
-		ErrLog: log.New(stdErr, "", 0),
+		Log:        log.New(stdOut, "", 0),
+		Out:        stdOut,
+		ErrLog:     log.New(stdErr, "", 0),
+		SummaryLog: log.New(stdOut, "", 0),
 	}
 	return
 }
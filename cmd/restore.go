@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Shopify/themekit/src/cmdutil"
+	"github.com/Shopify/themekit/src/colors"
+)
+
+var (
+	// confirmInput is where restore reads the confirmation from, it is a variable
+	// so that tests can answer the prompt.
+	confirmInput io.Reader = os.Stdin
+	// confirmOutput is where the prompt is written. It does not go through the
+	// logger so that the prompt is still shown with --quiet or --log-level.
+	confirmOutput io.Writer = os.Stderr
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore --from <directory>",
+	Short: "Replace the theme on shopify with a backup",
+	Long: `Restore will deploy a directory that was created with deploy --backup over
+ the theme on shopify. The backup is treated as the source of truth so any files
+ that are not in the backup are removed from shopify unless --nodelete is passed.
+ Restore asks for confirmation before changing anything unless --yes is passed.
+ `,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flags.From == "" {
+			return fmt.Errorf("please specify the backup directory to restore with --from")
+		}
+		flags.Directory = flags.From
+		return cmdutil.ForSingleClient(flags, []string{}, restore)
+	},
+}
+
+func restore(ctx *cmdutil.Ctx) error {
+	if !ctx.Flags.Yes {
+		msg := fmt.Sprintf("[%s] Replace theme %s on %s with %s? [y/N] ", ctx.Env.Name, ctx.Env.ThemeID, ctx.Env.Domain, ctx.Env.Directory)
+		if !confirm(msg) {
+			ctx.DisableSummary()
+			return fmt.Errorf("[%s] restore cancelled, pass --yes to restore without confirmation", colors.Green(ctx.Env.Name))
+		}
+	}
+	return deploy(ctx)
+}
+
+// confirm prints the message and returns true only if the answer is yes
+func confirm(msg string) bool {
+	fmt.Fprint(confirmOutput, msg)
+	answer, _ := bufio.NewReader(confirmInput).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
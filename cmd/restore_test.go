@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Shopify/themekit/src/shopify"
+)
+
+func TestRestore(t *testing.T) {
+	defer func(original io.Reader) { confirmInput = original }(confirmInput)
+	defer func(original io.Writer) { confirmOutput = original }(confirmOutput)
+	prompt := bytes.NewBufferString("")
+	confirmOutput = prompt
+
+	confirmInput = strings.NewReader("n\n")
+	ctx, client, _, _, _ := createTestCtx()
+	ctx.Env.Directory = filepath.Join("_testdata", "projectdir")
+	ctx.Flags.Quiet = true
+	err := restore(ctx)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "restore cancelled")
+	}
+	assert.Contains(t, prompt.String(), filepath.Join("_testdata", "projectdir")+"? [y/N]")
+	client.AssertNotCalled(t, "GetAllAssets")
+
+	confirmInput = strings.NewReader("")
+	ctx, client, _, _, _ = createTestCtx()
+	assert.NotNil(t, restore(ctx))
+	client.AssertNotCalled(t, "GetAllAssets")
+
+	confirmInput = strings.NewReader("Yes\n")
+	ctx, client, _, _, _ = createTestCtx()
+	ctx.Env.Directory = filepath.Join("_testdata", "projectdir")
+	client.On("GetAllAssets").Return([]shopify.Asset{}, fmt.Errorf("server error"))
+	assert.EqualError(t, restore(ctx), "server error")
+
+	prompt.Reset()
+	confirmInput = strings.NewReader("")
+	ctx, client, _, _, _ = createTestCtx()
+	ctx.Env.Directory = filepath.Join("_testdata", "projectdir")
+	ctx.Flags.Yes = true
+	client.On("GetAllAssets").Return([]shopify.Asset{}, fmt.Errorf("server error"))
+	assert.EqualError(t, restore(ctx), "server error")
+	assert.NotContains(t, prompt.String(), "[y/N]")
+}
@@ -39,16 +39,15 @@ Complete documentation is available at https://shopify.dev/tools/theme-kit.`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// the first config is the project config that is watched and saved to
+			flags.ConfigPath = flags.ConfigPaths[0]
 			if !flags.DisableUpdateNotifier && release.IsUpdateAvailable() {
 				colors.ColorStdOut.Print(colors.Yellow("An update for Themekit is available. To update please run `theme update`"))
 			}
 		},
 		PersistentPostRun: func(cmd *cobra.Command, args []string) {
-			// env validation requires a theme id. setting a dummy one here if not provided
-			if flags.ThemeID == "" {
-				flags.ThemeID = "1337"
-			}
-			cmdutil.ForDefaultClient(flags, args, func(ctx *cmdutil.Ctx) error {
+			// the notice only needs the password so it does not need a theme
+			cmdutil.ForDefaultClient(withoutTheme(flags), args, func(ctx *cmdutil.Ctx) error {
 				if !flags.DisableThemeKitAccessNotifier && !util.IsThemeAccessPassword(ctx.Env.Password) {
 					colors.ColorStdOut.Print(colors.Yellow("* Build themes without private apps. Learn more about the Theme Access app: https://shopify.dev/themes/tools/theme-access"))
 				}
@@ -87,35 +86,71 @@ func init() {
 	pwd, _ := os.Getwd()
 	defaultConfigPath := filepath.Join(pwd, "config.yml")
 
-	ThemeCmd.PersistentFlags().StringVarP(&flags.ConfigPath, "config", "c", defaultConfigPath, "path to config.yml")
+	ThemeCmd.PersistentFlags().StringArrayVarP(&flags.ConfigPaths, "config", "c", []string{defaultConfigPath}, "path to config.yml, use the flag multiple times to merge config files with later files overriding earlier ones.")
 	ThemeCmd.PersistentFlags().StringVar(&flags.VariableFilePath, "vars", "", "path to an file that defines environment variables")
+	ThemeCmd.PersistentFlags().StringArrayVar(&flags.Variables, "var", []string{}, "a key=value pair that will replace ${key} in the config file, use the flag multiple times to set multiple.")
 	ThemeCmd.PersistentFlags().StringArrayVarP(&flags.Environments, "env", "e", []string{env.Default.Name}, "environment to run the command")
 	ThemeCmd.PersistentFlags().StringVarP(&flags.Directory, "dir", "d", "", "directory that command will take effect. (default current directory)")
 	ThemeCmd.PersistentFlags().StringVarP(&flags.Password, "password", "p", "", "theme password. This will override what is in your config.yml")
+	ThemeCmd.PersistentFlags().StringVar(&flags.AccessToken, "access-token", "", "admin api access token for a custom app. This will override what is in your config.yml")
 	ThemeCmd.PersistentFlags().StringVarP(&flags.ThemeID, "themeid", "t", "", "theme id. This will override what is in your config.yml")
 	ThemeCmd.PersistentFlags().StringVarP(&flags.Domain, "store", "s", "", "your shopify domain. This will override what is in your config.yml")
 	ThemeCmd.PersistentFlags().StringVar(&flags.Proxy, "proxy", "", "proxy for all theme requests. This will override what is in your config.yml")
 	ThemeCmd.PersistentFlags().DurationVar(&flags.Timeout, "timeout", 0, "the timeout to kill any stalled processes. This will override what is in your config.yml")
+	ThemeCmd.PersistentFlags().DurationVar(&flags.ConnectTimeout, "connect-timeout", 0, "the timeout for establishing a connection to shopify. This will override what is in your config.yml")
 	ThemeCmd.PersistentFlags().BoolVarP(&flags.Verbose, "verbose", "v", false, "Enable more verbose output from the running command.")
+	ThemeCmd.PersistentFlags().BoolVarP(&flags.Quiet, "quiet", "q", false, "Only output errors and the summary of the running command.")
+	ThemeCmd.PersistentFlags().StringVar(&flags.LogLevel, "log-level", "info", "the minimum level of output to log, one of debug, info, warn or error.")
+	ThemeCmd.PersistentFlags().StringVar(&flags.LogFormat, "log-format", "text", "the format of log output, either text or json.")
+	ThemeCmd.PersistentFlags().BoolVar(&flags.NoProgress, "no-progress", false, "Disable the progress output for commands that transfer many files.")
+	ThemeCmd.PersistentFlags().BoolVar(&flags.JSON, "json", false, "Output one json object per file result to stdout, all other output is sent to stderr.")
 	ThemeCmd.PersistentFlags().BoolVarP(&flags.DisableUpdateNotifier, "no-update-notifier", "", false, "Stop theme kit from notifying about updates.")
+	ThemeCmd.PersistentFlags().BoolVar(&flags.DisableUpdateNotifier, "no-update-check", false, "Alias for --no-update-notifier.")
 	ThemeCmd.PersistentFlags().StringArrayVar(&flags.IgnoredFiles, "ignored-file", []string{}, "A single file to ignore, use the flag multiple times to add multiple.")
 	ThemeCmd.PersistentFlags().StringArrayVar(&flags.Ignores, "ignores", []string{}, "A path to a file that contains ignore patterns.")
 	ThemeCmd.PersistentFlags().BoolVar(&flags.DisableIgnore, "no-ignore", false, "Will disable config ignores so that all files can be changed")
 	ThemeCmd.PersistentFlags().BoolVar(&flags.AllowLive, "allow-live", false, "Will allow themekit to make changes to the live theme on the store.")
+	ThemeCmd.PersistentFlags().BoolVar(&flags.SkipThemeCheck, "skip-theme-check", false, "Do not check that the theme id exists in the shop before running the command.")
 	ThemeCmd.PersistentFlags().BoolVarP(&flags.DisableThemeKitAccessNotifier, "no-theme-kit-access-notifier", "", false, "Stop theme kit from notifying about Theme Access.")
 
 	watchCmd.Flags().StringVarP(&flags.Notify, "notify", "n", "", "file to touch or url to notify when a file has been changed")
+	watchCmd.Flags().DurationVar(&flags.Debounce, "debounce", 0, "how long to wait for more changes to a file before uploading it, defaults to 200ms. This will override what is in your config.yml")
+	deployCmd.Flags().StringVar(&flags.Notify, "notify", "", "file to touch or url to notify once the deploy has succeeded")
+	removeCmd.Flags().StringVar(&flags.Notify, "notify", "", "file to touch or url to notify once the files have been removed")
 	watchCmd.Flags().BoolVarP(&flags.AllEnvs, "allenvs", "a", false, "run command with all environments")
 	removeCmd.Flags().BoolVarP(&flags.AllEnvs, "allenvs", "a", false, "run command with all environments")
+	removeCmd.Flags().BoolVar(&flags.AllowEmpty, "allow-empty", false, "do nothing instead of failing when no files are given, like when the file list comes from a script")
 	openCmd.Flags().BoolVarP(&flags.AllEnvs, "allenvs", "a", false, "run command with all environments")
 	downloadCmd.Flags().BoolVarP(&flags.AllEnvs, "allenvs", "a", false, "run command with all environments")
 	deployCmd.Flags().BoolVarP(&flags.AllEnvs, "allenvs", "a", false, "run command with all environments")
+	deployCmd.Flags().IntVar(&flags.Workers, "workers", defaultWorkers, "number of files to transfer at the same time")
+	downloadCmd.Flags().IntVar(&flags.Workers, "workers", defaultWorkers, "number of files to transfer at the same time")
 	updateCmd.Flags().StringVar(&flags.Version, "version", "latest", "version of themekit to install")
 	newCmd.Flags().StringVarP(&flags.Name, "name", "n", "", "a name to define your theme on your shopify admin")
+	newCmd.Flags().BoolVarP(&flags.Force, "force", "f", false, "generate the theme even if the directory is not empty")
+	deployCmd.Flags().BoolVarP(&flags.Force, "force", "f", false, "upload every file even if its checksum matches the remote file.")
+	downloadCmd.Flags().BoolVarP(&flags.Force, "force", "f", false, "download every file even if its checksum matches the local file.")
+	downloadCmd.Flags().BoolVar(&flags.NoPreserveModTime, "no-preserve-mtime", false, "set the modification time of downloaded files to now instead of when they were updated on shopify.")
 	openCmd.Flags().BoolVarP(&flags.Edit, "edit", "E", false, "open the web editor for the theme.")
 	openCmd.Flags().StringVarP(&flags.With, "browser", "b", "", "name of the browser to open the url. the name should match the name of browser on your system.")
 	getCmd.Flags().BoolVarP(&flags.List, "list", "l", false, "list available themes.")
+	getCmd.Flags().StringVarP(&flags.Name, "name", "n", "", "the name of the theme to get.")
+	listCmd.Flags().StringVar(&flags.Sort, "sort", "name", "sort the files by name or date.")
+	listCmd.Flags().BoolVarP(&flags.AllEnvs, "allenvs", "a", false, "run command with all environments")
+	verifyCmd.Flags().BoolVarP(&flags.AllEnvs, "allenvs", "a", false, "verify all environments")
 	deployCmd.Flags().BoolVarP(&flags.NoDelete, "nodelete", "n", false, "do not delete files on shopify during deploy.")
+	deployCmd.Flags().BoolVar(&flags.SyncDeletes, "sync-deletes", false, "when deploying specific paths, delete files on shopify under those paths that do not exist locally.")
+	deployCmd.Flags().BoolVar(&flags.WithSettings, "with-settings", false, "upload config/settings_data.json even if it has not changed.")
+	deployCmd.Flags().BoolVar(&flags.NoSettings, "no-settings", false, "do not upload or remove config/settings_data.json.")
+	deployCmd.Flags().StringVar(&flags.Backup, "backup", "", "download the remote theme into a timestamped directory before deploying, defaults to a directory next to the project.")
+	deployCmd.Flags().Lookup("backup").NoOptDefVal = defaultBackupFlag
+	deployCmd.Flags().BoolVar(&flags.DryRun, "dry-run", false, "print the changes deploy would make without changing anything on shopify.")
+	deployCmd.Flags().BoolVar(&flags.Lint, "lint", false, "check liquid files for syntax errors before uploading them.")
+	watchCmd.Flags().BoolVar(&flags.Lint, "lint", false, "check liquid files for syntax errors before uploading them.")
+	restoreCmd.Flags().StringVar(&flags.From, "from", "", "the backup directory to restore.")
+	restoreCmd.Flags().BoolVarP(&flags.Yes, "yes", "y", false, "restore without asking for confirmation.")
+	restoreCmd.Flags().BoolVarP(&flags.NoDelete, "nodelete", "n", false, "do not delete files on shopify that are not in the backup.")
+	restoreCmd.Flags().IntVar(&flags.Workers, "workers", defaultWorkers, "number of files to transfer at the same time")
 	openCmd.Flags().BoolVar(&flags.HidePreviewBar, "hidepb", false, "run command with all environments")
 
 	getCmd.Flags().BoolVar(&flags.Live, "live", false, "will allow themekit to autofill the theme ID as the currently published theme ID")
@@ -123,15 +158,21 @@ func init() {
 	configureCmd.Flags().BoolVar(&flags.Live, "live", false, "will allow themekit to autofill the theme ID as the currently published theme ID")
 
 	ThemeCmd.AddCommand(
+		configCmd,
 		configureCmd,
 		deployCmd,
+		diffCmd,
 		downloadCmd,
 		getCmd,
+		listCmd,
 		newCmd,
 		openCmd,
 		publishCmd,
 		removeCmd,
+		restoreCmd,
+		themesCmd,
 		updateCmd,
+		verifyCmd,
 		versionCmd,
 		watchCmd,
 	)
@@ -6,6 +6,7 @@ import (
 	"runtime/pprof"
 
 	"github.com/Shopify/themekit/cmd"
+	"github.com/Shopify/themekit/src/cmdutil"
 	"github.com/Shopify/themekit/src/colors"
 )
 
@@ -27,7 +28,7 @@ func main() {
 	}
 
 	if err := cmd.ThemeCmd.Execute(); err != nil {
-		stdErr.Fatal(colors.Red(err.Error()))
+		stdErr.Fatal(colors.Red(cmdutil.Redact(err.Error())))
 	}
 
 	if memProfile := os.Getenv(memProfileVar); memProfile != "" {
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Shopify/themekit/src/cmdutil"
+	"github.com/Shopify/themekit/src/colors"
+	"github.com/Shopify/themekit/src/shopify"
+)
+
+var themesCmd = &cobra.Command{
+	Use:   "themes",
+	Short: "List the themes in the store",
+	Long: `Themes will print the id, name, role and the last update time of every theme
+ in the store. It only needs a store and a password so it can be used to find the
+ theme id to put in a new config.
+ `,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withThemes(flags, args, printThemes)
+	},
+}
+
+// listedTheme is the output of a single theme when the --json flag is set
+type listedTheme struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func printThemes(ctx *cmdutil.Ctx, themes []shopify.Theme) error {
+	ctx.DisableSummary()
+	for _, theme := range themes {
+		if ctx.Flags.JSON {
+			out, _ := json.Marshal(listedTheme{ID: theme.ID, Name: theme.Name, Role: theme.Role, UpdatedAt: theme.UpdatedAt})
+			fmt.Fprintln(ctx.Out, string(out))
+		} else {
+			role := theme.Role
+			if role == "main" {
+				role = colors.Green("live")
+			}
+			fmt.Fprintf(ctx.Out, "[%s] %s %s %s\n", colors.Yellow(theme.ID), colors.Blue(theme.Name), role, theme.UpdatedAt)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Shopify/themekit/src/colors"
+	"github.com/Shopify/themekit/src/shopify"
+)
+
+func TestPrintThemes(t *testing.T) {
+	themes := []shopify.Theme{
+		{ID: 123, Name: "Debut", Role: "main", UpdatedAt: "2020-01-02T03:04:05-05:00"},
+		{ID: 456, Name: "Staging", Role: "unpublished"},
+	}
+
+	ctx, _, _, stdOut, _ := createTestCtx()
+	assert.Nil(t, printThemes(ctx, themes))
+	assert.Contains(t, stdOut.String(), colors.Blue("Debut")+" "+colors.Green("live")+" 2020-01-02T03:04:05-05:00")
+	assert.Contains(t, stdOut.String(), colors.Blue("Staging")+" unpublished")
+
+	ctx, _, _, stdOut, _ = createTestCtx()
+	out := bytes.NewBufferString("")
+	ctx.Out = out
+	ctx.Flags.JSON = true
+	assert.Nil(t, printThemes(ctx, themes))
+	assert.Equal(t, "", stdOut.String())
+	assert.Equal(t, `{"id":123,"name":"Debut","role":"main","updated_at":"2020-01-02T03:04:05-05:00"}
+{"id":456,"name":"Staging","role":"unpublished","updated_at":""}
+`, out.String())
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Shopify/themekit/src/cmdutil"
+	"github.com/Shopify/themekit/src/colors"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that environments are valid and can connect to shopify",
+	Long: `Verify will validate the config for every environment and make sure it
+ can authenticate with shopify without changing anything. It reports a result for
+ each environment and fails if any of them could not be verified, which makes it
+ useful as a check before deploying in CI.
+ `,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// verifying does not change the theme so it should not care about the live theme
+		flags.AllowLive = true
+		statuses, err := cmdutil.VerifyEnvironments(flags)
+		if err != nil {
+			return err
+		}
+		return reportVerification(colors.ColorStdOut, statuses)
+	},
+}
+
+func reportVerification(out *log.Logger, statuses []cmdutil.EnvStatus) error {
+	failed := 0
+	for _, status := range statuses {
+		if status.Err != nil {
+			failed++
+			out.Printf("[%s] %s %s", colors.Green(status.Name), colors.Red("failed:"), cmdutil.Redact(status.Err.Error()))
+		} else {
+			out.Printf("[%s] %s", colors.Green(status.Name), colors.Cyan("ok"))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%v of %v environments failed verification", failed, len(statuses))
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Shopify/themekit/src/cmdutil"
+)
+
+func TestReportVerification(t *testing.T) {
+	out := bytes.NewBufferString("")
+	err := reportVerification(log.New(out, "", 0), []cmdutil.EnvStatus{{Name: "development"}, {Name: "production"}})
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "development")
+	assert.Contains(t, out.String(), "ok")
+
+	out.Reset()
+	err = reportVerification(log.New(out, "", 0), []cmdutil.EnvStatus{{Name: "development"}, {Name: "production", Err: fmt.Errorf("invalid password")}})
+	assert.EqualError(t, err, "1 of 2 environments failed verification")
+	assert.Contains(t, out.String(), "invalid password")
+}
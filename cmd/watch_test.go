@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
 	"testing"
 
@@ -15,8 +14,8 @@ import (
 
 type testAdapter struct{ mock.Mock }
 
-func (adapter *testAdapter) notify(ctx *cmdutil.Ctx, path string) {
-	adapter.Called(ctx, path)
+func (adapter *testAdapter) notify(ctx *cmdutil.Ctx, event string, files []string) {
+	adapter.Called(ctx, event, files)
 }
 
 func TestWatch(t *testing.T) {
@@ -47,7 +46,7 @@ func TestWatch(t *testing.T) {
 		signalChan <- os.Interrupt
 	}()
 	notifier := new(testAdapter)
-	notifier.On("notify", ctx, "assets/app.js")
+	notifier.On("notify", ctx, "update", []string{"assets/app.js"})
 	err = watch(ctx, eventChan, signalChan, notifier)
 	assert.Nil(t, err)
 	assert.Contains(t, stdOut.String(), "Watching for file changes")
@@ -58,7 +57,7 @@ func TestWatch(t *testing.T) {
 	signalChan = make(chan os.Signal)
 	eventChan = make(chan file.Event)
 	ctx, client, _, stdOut, stdErr := createTestCtx()
-	client.On("UpdateAsset", shopify.Asset{Key: "assets/app.js", Checksum: "d41d8cd98f00b204e9800998ecf8427e"}, "").Return(nil)
+	client.On("UpdateAsset", mock.Anything, shopify.Asset{Key: "assets/app.js", Checksum: "d41d8cd98f00b204e9800998ecf8427e"}, "").Return(nil)
 	ctx.Flags.ConfigPath = "config.yml"
 	ctx.Env.Directory = "_testdata/projectdir"
 	go func() {
@@ -66,7 +65,7 @@ func TestWatch(t *testing.T) {
 		signalChan <- os.Interrupt
 	}()
 	notifier = new(testAdapter)
-	notifier.On("notify", ctx, "assets/app.js")
+	notifier.On("notify", ctx, "update", []string{"assets/app.js"})
 	err = watch(ctx, eventChan, signalChan, notifier)
 	assert.Nil(t, err)
 	assert.Contains(t, stdOut.String(), "Watching for file changes")
@@ -77,7 +76,7 @@ func TestWatch(t *testing.T) {
 	signalChan = make(chan os.Signal)
 	eventChan = make(chan file.Event)
 	ctx, client, _, stdOut, stdErr = createTestCtx()
-	client.On("DeleteAsset", shopify.Asset{Key: "assets/app.js"}).Return(nil)
+	client.On("DeleteAsset", mock.Anything, shopify.Asset{Key: "assets/app.js"}).Return(nil)
 	ctx.Flags.ConfigPath = "config.yml"
 	ctx.Env.Directory = "_testdata/projectdir"
 	go func() {
@@ -85,7 +84,7 @@ func TestWatch(t *testing.T) {
 		signalChan <- os.Interrupt
 	}()
 	notifier = new(testAdapter)
-	notifier.On("notify", ctx, "assets/app.js")
+	notifier.On("notify", ctx, "remove", []string{"assets/app.js"})
 	err = watch(ctx, eventChan, signalChan, notifier)
 	assert.Nil(t, err)
 	assert.Contains(t, stdOut.String(), "Watching for file changes")
@@ -96,7 +95,7 @@ func TestWatch(t *testing.T) {
 	signalChan = make(chan os.Signal)
 	eventChan = make(chan file.Event)
 	ctx, client, _, stdOut, stdErr = createTestCtx()
-	client.On("UpdateAsset", shopify.Asset{Key: "assets/app.js", Checksum: "d41d8cd98f00b204e9800998ecf8427e"}, "").Return(nil)
+	client.On("UpdateAsset", mock.Anything, shopify.Asset{Key: "assets/app.js", Checksum: "d41d8cd98f00b204e9800998ecf8427e"}, "").Return(nil)
 	ctx.Flags.ConfigPath = "config.yml"
 	ctx.Env.Directory = "_testdata/projectdir"
 	go func() {
@@ -105,7 +104,7 @@ func TestWatch(t *testing.T) {
 		eventChan <- file.Event{Op: file.Remove, Path: "assets/app.js"}
 	}()
 	notifier = new(testAdapter)
-	notifier.On("notify", ctx, "assets/app.js")
+	notifier.On("notify", ctx, "update", []string{"assets/app.js"})
 	err = watch(ctx, eventChan, signalChan, notifier)
 	assert.Nil(t, err)
 	assert.Contains(t, stdOut.String(), "Watching for file changes")
@@ -114,50 +113,3 @@ func TestWatch(t *testing.T) {
 	assert.NotContains(t, stdOut.String(), "Deleted assets/app.js")
 	notifier.AssertExpectations(t)
 }
-
-func TestPerform(t *testing.T) {
-	key := "assets/app.js"
-
-	ctx, m, _, _, se := createTestCtx()
-	perform(ctx, "bad", file.Update, "")
-	assert.Contains(t, se.String(), "readAsset: ")
-	m.AssertExpectations(t)
-
-	ctx, m, _, _, se = createTestCtx()
-	ctx.Env.Directory = "_testdata/projectdir"
-	m.On("UpdateAsset", shopify.Asset{Key: key, Checksum: "d41d8cd98f00b204e9800998ecf8427e"}, "").Return(fmt.Errorf("shopify says no update"), "")
-	perform(ctx, key, file.Update, "")
-	assert.Contains(t, se.String(), "shopify says no update")
-	m.AssertExpectations(t)
-
-	ctx, m, _, so, _ := createTestCtx()
-	ctx.Env.Directory = "_testdata/projectdir"
-	m.On("UpdateAsset", shopify.Asset{Key: key, Checksum: "d41d8cd98f00b204e9800998ecf8427e"}, "").Return(nil)
-	perform(ctx, key, file.Update, "")
-	assert.NotContains(t, so.String(), "Updated")
-	m.AssertExpectations(t)
-
-	ctx, m, _, so, _ = createTestCtx()
-	ctx.Env.Directory = "_testdata/projectdir"
-	ctx.Flags.Verbose = true
-	m.On("UpdateAsset", shopify.Asset{Key: key, Checksum: "d41d8cd98f00b204e9800998ecf8427e"}, "").Return(nil)
-	perform(ctx, key, file.Update, "")
-	assert.Contains(t, so.String(), "Updated")
-	m.AssertExpectations(t)
-
-	ctx, m, _, so, se = createTestCtx()
-	m.On("DeleteAsset", mock.MatchedBy(func(a shopify.Asset) bool { return a.Key == "good" })).Return(nil)
-	m.On("DeleteAsset", mock.MatchedBy(func(a shopify.Asset) bool { return a.Key == "bad" })).Return(fmt.Errorf("shopify says no update"))
-
-	perform(ctx, "bad", file.Remove, "")
-	assert.Contains(t, se.String(), "shopify says no update")
-
-	perform(ctx, "good", file.Remove, "")
-	assert.NotContains(t, so.String(), "Deleted")
-
-	ctx.Flags.Verbose = true
-	perform(ctx, "good", file.Remove, "")
-	assert.Contains(t, so.String(), "Deleted")
-
-	m.AssertExpectations(t)
-}
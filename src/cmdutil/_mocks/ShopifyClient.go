@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	shopify "github.com/Shopify/themekit/src/shopify"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -33,13 +35,13 @@ func (_m *ShopifyClient) CreateNewTheme(_a0 string) (shopify.Theme, error) {
 	return r0, r1
 }
 
-// DeleteAsset provides a mock function with given fields: _a0
-func (_m *ShopifyClient) DeleteAsset(_a0 shopify.Asset) error {
-	ret := _m.Called(_a0)
+// DeleteAsset provides a mock function with given fields: _a0, _a1
+func (_m *ShopifyClient) DeleteAsset(_a0 context.Context, _a1 shopify.Asset) error {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(shopify.Asset) error); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, shopify.Asset) error); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -70,20 +72,20 @@ func (_m *ShopifyClient) GetAllAssets() ([]shopify.Asset, error) {
 	return r0, r1
 }
 
-// GetAsset provides a mock function with given fields: _a0
-func (_m *ShopifyClient) GetAsset(_a0 string) (shopify.Asset, error) {
-	ret := _m.Called(_a0)
+// GetAsset provides a mock function with given fields: _a0, _a1
+func (_m *ShopifyClient) GetAsset(_a0 context.Context, _a1 string) (shopify.Asset, error) {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 shopify.Asset
-	if rf, ok := ret.Get(0).(func(string) shopify.Asset); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, string) shopify.Asset); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		r0 = ret.Get(0).(shopify.Asset)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -170,13 +172,13 @@ func (_m *ShopifyClient) Themes() ([]shopify.Theme, error) {
 	return r0, r1
 }
 
-// UpdateAsset provides a mock function with given fields: _a0, _a1
-func (_m *ShopifyClient) UpdateAsset(_a0 shopify.Asset, _a1 string) error {
-	ret := _m.Called(_a0, _a1)
+// UpdateAsset provides a mock function with given fields: _a0, _a1, _a2
+func (_m *ShopifyClient) UpdateAsset(_a0 context.Context, _a1 shopify.Asset, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(shopify.Asset, string) error); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, shopify.Asset, string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Error(0)
 	}
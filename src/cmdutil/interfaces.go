@@ -1,6 +1,8 @@
 package cmdutil
 
 import (
+	"context"
+
 	"github.com/Shopify/themekit/src/env"
 	"github.com/Shopify/themekit/src/shopify"
 )
@@ -12,9 +14,9 @@ type shopifyClient interface {
 	PublishTheme() error
 	Themes() ([]shopify.Theme, error)
 	GetAllAssets() ([]shopify.Asset, error)
-	GetAsset(string) (shopify.Asset, error)
-	UpdateAsset(shopify.Asset, string) error
-	DeleteAsset(shopify.Asset) error
+	GetAsset(context.Context, string) (shopify.Asset, error)
+	UpdateAsset(context.Context, shopify.Asset, string) error
+	DeleteAsset(context.Context, shopify.Asset) error
 }
 
 type config interface {
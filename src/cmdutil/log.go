@@ -0,0 +1,143 @@
+package cmdutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-colorable"
+
+	"github.com/Shopify/themekit/src/httpify"
+	"github.com/Shopify/themekit/src/release"
+)
+
+const (
+	levelDebug = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+var logLevels = map[string]int{
+	"debug": levelDebug,
+	"info":  levelInfo,
+	"warn":  levelWarn,
+	"error": levelError,
+}
+
+// configureLogging validates the logging flags and applies the settings that are
+// global to the process, like disabling colors and logging requests.
+func configureLogging(flags Flags) error {
+	if flags.Quiet && flags.Verbose {
+		return fmt.Errorf("--quiet and --verbose cannot be used together")
+	}
+	if _, ok := logLevels[logLevel(flags)]; !ok {
+		return fmt.Errorf("invalid log level %v, must be one of debug, info, warn or error", flags.LogLevel)
+	}
+
+	switch flags.LogFormat {
+	case "", "text":
+	case "json":
+		color.NoColor = true
+	default:
+		return fmt.Errorf("invalid log format %v, must be either text or json", flags.LogFormat)
+	}
+
+	if logLevel(flags) == "debug" {
+		debugLog := newLogger(flags, levelDebug, colorable.NewColorableStderr())
+		debugLog.Printf("themekit %s %s/%s", release.ThemeKitVersion, runtime.GOOS, runtime.GOARCH)
+		httpify.SetDebugLog(debugLog)
+	} else {
+		httpify.SetDebugLog(nil)
+	}
+
+	return nil
+}
+
+// stdLog returns the logger for regular output. When outputting json all logs
+// are sent to stderr so that stdout only contains the results.
+func stdLog(flags Flags) *log.Logger {
+	return newLogger(flags, levelInfo, stdOutput(flags))
+}
+
+// warnLog returns the logger for warnings, they are written to the same place as
+// regular output.
+func warnLog(flags Flags) *log.Logger {
+	return newLogger(flags, levelWarn, stdOutput(flags))
+}
+
+// summaryLog returns the logger for the summary of a command, it is output even
+// when the output is quiet.
+func summaryLog(flags Flags) *log.Logger {
+	return newLogger(flags, levelError, stdOutput(flags))
+}
+
+// errLog returns the logger for errors, these are always output.
+func errLog(flags Flags) *log.Logger {
+	return newLogger(flags, levelError, colorable.NewColorableStderr())
+}
+
+func stdOutput(flags Flags) io.Writer {
+	if flags.JSON {
+		return colorable.NewColorableStderr()
+	}
+	return colorable.NewColorableStdout()
+}
+
+func newLogger(flags Flags, level int, out io.Writer) *log.Logger {
+	if minLevel, ok := logLevels[logLevel(flags)]; ok && level < minLevel {
+		out = ioutil.Discard
+	} else if flags.LogFormat == "json" {
+		out = jsonLogWriter{out: out, level: levelName(level)}
+	}
+	return log.New(redactWriter{out: out}, "", 0)
+}
+
+func logLevel(flags Flags) string {
+	if flags.Quiet {
+		return "error"
+	} else if flags.LogLevel == "" {
+		return "info"
+	}
+	return strings.ToLower(flags.LogLevel)
+}
+
+func levelName(level int) string {
+	for name, value := range logLevels {
+		if value == level {
+			return name
+		}
+	}
+	return "info"
+}
+
+// jsonLogWriter writes every log line out as a json object
+type jsonLogWriter struct {
+	out   io.Writer
+	level string
+}
+
+func (w jsonLogWriter) Write(p []byte) (int, error) {
+	line, err := json.Marshal(struct {
+		Time    string `json:"time"`
+		Level   string `json:"level"`
+		Message string `json:"msg"`
+	}{
+		Time:    time.Now().Format(time.RFC3339),
+		Level:   w.level,
+		Message: strings.TrimRight(string(p), "\n"),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if _, err = w.out.Write(append(line, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
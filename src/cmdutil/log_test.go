@@ -0,0 +1,66 @@
+package cmdutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureLogging(t *testing.T) {
+	noColor := color.NoColor
+	defer func() { color.NoColor = noColor }()
+
+	assert.Nil(t, configureLogging(Flags{}))
+	assert.Nil(t, configureLogging(Flags{LogLevel: "WARN", LogFormat: "text"}))
+	assert.EqualError(t, configureLogging(Flags{LogLevel: "loud"}), "invalid log level loud, must be one of debug, info, warn or error")
+	assert.EqualError(t, configureLogging(Flags{LogFormat: "xml"}), "invalid log format xml, must be either text or json")
+	assert.EqualError(t, configureLogging(Flags{Quiet: true, Verbose: true}), "--quiet and --verbose cannot be used together")
+
+	color.NoColor = false
+	assert.Nil(t, configureLogging(Flags{LogFormat: "json"}))
+	assert.True(t, color.NoColor)
+	assert.Nil(t, configureLogging(Flags{LogLevel: "debug"}))
+	assert.Nil(t, configureLogging(Flags{}))
+}
+
+func TestNewLogger(t *testing.T) {
+	testcases := []struct {
+		level   string
+		logAt   int
+		written bool
+	}{
+		{level: "", logAt: levelInfo, written: true},
+		{level: "", logAt: levelDebug, written: false},
+		{level: "debug", logAt: levelDebug, written: true},
+		{level: "warn", logAt: levelInfo, written: false},
+		{level: "warn", logAt: levelWarn, written: true},
+		{level: "error", logAt: levelWarn, written: false},
+		{level: "error", logAt: levelError, written: true},
+	}
+
+	for _, testcase := range testcases {
+		out := bytes.NewBufferString("")
+		newLogger(Flags{LogLevel: testcase.level}, testcase.logAt, out).Print("hello")
+		assert.Equal(t, testcase.written, out.Len() > 0, "%v %v", testcase.level, testcase.logAt)
+	}
+
+	out := bytes.NewBufferString("")
+	newLogger(Flags{Quiet: true}, levelWarn, out).Print("hello")
+	assert.Equal(t, 0, out.Len())
+	newLogger(Flags{Quiet: true}, levelError, out).Print("hello")
+	assert.True(t, out.Len() > 0)
+}
+
+func TestJSONLogFormat(t *testing.T) {
+	out := bytes.NewBufferString("")
+	newLogger(Flags{LogFormat: "json"}, levelWarn, out).Printf("[%s] careful", "development")
+
+	var line map[string]string
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "warn", line["level"])
+	assert.Equal(t, "[development] careful", line["msg"])
+	assert.NotEmpty(t, line["time"])
+}
@@ -0,0 +1,65 @@
+package cmdutil
+
+import (
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Shopify/themekit/src/env"
+)
+
+const redacted = "***"
+
+// minSecretLength stops very short values from masking unrelated output
+const minSecretLength = 4
+
+var (
+	secretsMu      sync.RWMutex
+	secrets        = map[string]bool{}
+	urlCredentials = regexp.MustCompile(`(://[^/:@\s]+):[^/@\s]+@`)
+)
+
+// addSecrets registers the secrets of an environment so that they are masked in
+// all output.
+func addSecrets(e *env.Env) {
+	values := []string{e.Password, e.AccessToken}
+	if proxyURL, err := url.Parse(e.Proxy); err == nil && proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		values = append(values, password)
+	}
+
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, value := range values {
+		if len(value) >= minSecretLength {
+			secrets[value] = true
+		}
+	}
+}
+
+// Redact will mask any known secrets and any credentials embedded in urls in the
+// message so that they are not leaked into logs.
+func Redact(msg string) string {
+	msg = urlCredentials.ReplaceAllString(msg, "$1:"+redacted+"@")
+
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	for secret := range secrets {
+		msg = strings.Replace(msg, secret, redacted, -1)
+	}
+	return msg
+}
+
+// redactWriter masks secrets in everything written through it
+type redactWriter struct {
+	out io.Writer
+}
+
+func (w redactWriter) Write(p []byte) (int, error) {
+	if _, err := w.out.Write([]byte(Redact(string(p)))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/Shopify/themekit/src/colors"
 	"github.com/Shopify/themekit/src/file"
@@ -11,6 +12,8 @@ import (
 
 type cmdSummary struct {
 	actions, downloaded, uploaded, skipped, removed int32
+	bytes                                           int64
+	started                                         time.Time
 	disabled                                        bool
 	errors                                          []string
 }
@@ -29,6 +32,10 @@ func (sum *cmdSummary) completeOp(op file.Op) {
 	}
 }
 
+func (sum *cmdSummary) addBytes(n int) {
+	atomic.AddInt64(&sum.bytes, int64(n))
+}
+
 func (sum *cmdSummary) disable() {
 	sum.disabled = true
 }
@@ -61,7 +68,11 @@ func (sum *cmdSummary) display(ctx *Ctx) {
 	if len(sum.errors) > 0 {
 		results = append(results, fmt.Sprintf("%v: %v", colors.Red("Errored"), len(sum.errors)))
 	}
-	ctx.Log.Printf("[%v] %v", colors.Green(ctx.Env.Name), strings.Join(results, ", "))
+	line := strings.Join(results, ", ")
+	if transfer := sum.transfer(); transfer != "" {
+		line += " (" + transfer + ")"
+	}
+	ctx.SummaryLog.Printf("[%v] %v", colors.Green(ctx.Env.Name), line)
 	if len(sum.errors) > 0 {
 		ctx.ErrLog.Printf("[%s] %s", colors.Green(ctx.Env.Name), colors.Red("Errors encountered: "))
 		for _, msg := range sum.errors {
@@ -69,3 +80,34 @@ func (sum *cmdSummary) display(ctx *Ctx) {
 		}
 	}
 }
+
+// transfer describes how much data was transferred and how long it took
+func (sum *cmdSummary) transfer() string {
+	parts := []string{}
+	if bytes := atomic.LoadInt64(&sum.bytes); bytes > 0 {
+		parts = append(parts, formatBytes(bytes))
+	}
+	if !sum.started.IsZero() {
+		elapsed := time.Since(sum.started)
+		if elapsed < time.Second {
+			elapsed = elapsed.Round(time.Millisecond)
+		} else {
+			elapsed = elapsed.Round(100 * time.Millisecond)
+		}
+		parts = append(parts, fmt.Sprintf("in %v", elapsed))
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit && exp < 3; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGT"[exp])
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -77,12 +78,25 @@ func TestSummaryDisplay(t *testing.T) {
 	out, err = rundisplay(cmdSummary{actions: 23, errors: []string{"one", "two", "three"}})
 	assert.Equal(t, out, fmt.Sprintf("[sum] 23 files, Errored: 3\n"))
 	assert.Equal(t, err, "[sum] Errors encountered: \n\tone\n\ttwo\n\tthree\n")
+
+	out, _ = rundisplay(cmdSummary{actions: 23, uploaded: 23, bytes: 3250586})
+	assert.Equal(t, out, fmt.Sprintf("[sum] 23 files, Updated: 23 (3.1 MB)\n"))
+
+	out, _ = rundisplay(cmdSummary{actions: 23, uploaded: 23, started: time.Now().Add(-12 * time.Second)})
+	assert.Contains(t, out, "[sum] 23 files, Updated: 23 (in 12")
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", formatBytes(512))
+	assert.Equal(t, "1.5 KB", formatBytes(1536))
+	assert.Equal(t, "3.1 MB", formatBytes(3250586))
+	assert.Equal(t, "2.0 GB", formatBytes(2*1024*1024*1024))
 }
 
 func rundisplay(summary cmdSummary) (stdout, stderr string) {
 	stdOut := bytes.NewBufferString("")
 	stdErr := bytes.NewBufferString("")
-	ctx := &Ctx{Env: &env.Env{Name: "sum"}, Log: log.New(stdOut, "", 0), ErrLog: log.New(stdErr, "", 0)}
+	ctx := &Ctx{Env: &env.Env{Name: "sum"}, SummaryLog: log.New(stdOut, "", 0), ErrLog: log.New(stdErr, "", 0)}
 	summary.display(ctx)
 	return stdOut.String(), stdErr.String()
 }
@@ -1,14 +1,19 @@
 package cmdutil
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/mattn/go-isatty"
 	"github.com/ryanuber/go-glob"
 	"github.com/vbauerster/mpb"
 	"github.com/vbauerster/mpb/decor"
@@ -20,6 +25,10 @@ import (
 	"github.com/Shopify/themekit/src/shopify"
 )
 
+// contextConcurrency is the amount of environments that are connected to at the
+// same time so that many environments on one shop do not burst the api.
+const contextConcurrency = 4
+
 // ErrReload is an error to return from a command if you want to reload and run again
 var (
 	ErrReload        = errors.New("reloading config")
@@ -31,20 +40,30 @@ var (
 // command line. Some of the values are used across different commands
 type Flags struct {
 	ConfigPath                    string
+	ConfigPaths                   []string
 	VariableFilePath              string
+	Variables                     []string
 	Environments                  []string
 	Directory                     string
 	Password                      string
+	AccessToken                   string
 	ThemeID                       string
 	Domain                        string
 	Proxy                         string
 	Timeout                       time.Duration
+	ConnectTimeout                time.Duration
 	Verbose                       bool
+	Quiet                         bool
+	LogLevel                      string
+	LogFormat                     string
+	NoProgress                    bool
+	JSON                          bool
 	DisableUpdateNotifier         bool
 	IgnoredFiles                  []string
 	Ignores                       []string
 	DisableIgnore                 bool
 	Notify                        string
+	Debounce                      time.Duration
 	AllEnvs                       bool
 	Version                       string
 	Prefix                        string
@@ -53,8 +72,22 @@ type Flags struct {
 	Edit                          bool
 	With                          string
 	List                          bool
+	Sort                          string
 	NoDelete                      bool
+	SyncDeletes                   bool
+	WithSettings                  bool
+	NoSettings                    bool
+	DryRun                        bool
+	Lint                          bool
+	Backup                        string
+	From                          string
+	Yes                           bool
+	Force                         bool
+	NoPreserveModTime             bool
+	SkipThemeCheck                bool
+	Workers                       int
 	AllowLive                     bool
+	AllowEmpty                    bool
 	Live                          bool
 	HidePreviewBar                bool
 	DisableThemeKitAccessNotifier bool
@@ -62,25 +95,59 @@ type Flags struct {
 
 // Ctx is a specific context that a command will run in
 type Ctx struct {
-	Shop     shopify.Shop
-	Conf     config
-	Client   shopifyClient
-	Flags    Flags
-	Env      *env.Env
-	Args     []string
-	Log      *log.Logger
-	ErrLog   *log.Logger
-	progress *mpb.Progress
-	Bar      *mpb.Bar
-	mu       sync.RWMutex
-	summary  cmdSummary
+	Shop   shopify.Shop
+	Conf   config
+	Client shopifyClient
+	Flags  Flags
+	Env    *env.Env
+	Args   []string
+	Log    *log.Logger
+	ErrLog *log.Logger
+	// SummaryLog outputs the summary at the end of a command, even when quiet
+	SummaryLog *log.Logger
+	Out        io.Writer
+	progress   *mpb.Progress
+	Bar        *mpb.Bar
+	mu         sync.RWMutex
+	summary    cmdSummary
+	counter    progressCounter
+	// noResults stops file operations from being reported as results, like the
+	// downloads of a backup that are not part of the command's own work
+	noResults bool
+}
+
+// progressCounter tracks completed tasks when a progress bar cannot be drawn so
+// that progress can be logged periodically instead.
+type progressCounter struct {
+	total, done int32
+	lastReport  time.Time
+}
+
+// progressInterval is how often progress is logged when not outputting to a terminal
+var progressInterval = 5 * time.Second
+
+// isTerminal reports if stdout is a terminal that a progress bar can be drawn on
+var isTerminal = func() bool {
+	return isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+}
+
+// fileResult is the output of a single file operation when the --json flag is set
+type fileResult struct {
+	File        string `json:"file"`
+	Environment string `json:"environment"`
+	Event       string `json:"event"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	Host        string `json:"host"`
 }
 
 type clientFact func(*env.Env) (shopifyClient, error)
 
 func createCtx(newClient clientFact, conf env.Conf, e *env.Env, flags Flags, args []string, progress *mpb.Progress) (*Ctx, error) {
+	addSecrets(e)
+
 	if e.Proxy != "" {
-		colors.ColorStdOut.Printf(
+		warnLog(flags).Printf(
 			"[%s] Proxy URL detected from Configuration [%s] SSL Certificate Validation will be disabled!",
 			colors.Green(e.Name),
 			colors.Yellow(e.Proxy),
@@ -99,7 +166,7 @@ func createCtx(newClient clientFact, conf env.Conf, e *env.Env, flags Flags, arg
 
 	shop, err := client.GetShop()
 	if err != nil && err == shopify.ErrShopDomainNotFound {
-		colors.ColorStdErr.Printf(
+		errLog(flags).Printf(
 			"[%s] invalid credentials, the domain %s is not found",
 			colors.Green(e.Name),
 			colors.Yellow(e.Domain),
@@ -114,16 +181,27 @@ func createCtx(newClient clientFact, conf env.Conf, e *env.Env, flags Flags, arg
 		return &Ctx{}, err
 	}
 
+	if e.ThemeID == env.LiveThemeID {
+		if err := resolveLiveTheme(themes, e); err != nil {
+			return &Ctx{}, err
+		}
+		stdLog(flags).Printf("[%s] using the live theme %s", colors.Green(e.Name), colors.Yellow(e.ThemeID))
+		// the client has to be created again so that it uses the resolved theme id
+		if client, err = newClient(e); err != nil {
+			return &Ctx{}, err
+		}
+	}
+
 	for _, theme := range themes {
 		if theme.Role == "main" {
 			if fmt.Sprintf("%v", theme.ID) == e.ThemeID && flags.AllowLive {
-				colors.ColorStdOut.Printf(
+				warnLog(flags).Printf(
 					"[%s] Warning, this is the live theme on %s.",
 					colors.Yellow(e.Name),
 					colors.Yellow(shop.Name),
 				)
 			} else if fmt.Sprintf("%v", theme.ID) == e.ThemeID && !flags.AllowLive {
-				colors.ColorStdOut.Printf(
+				stdLog(flags).Printf(
 					"[%s] This is the live theme on %s. If you wish to make changes to it, then you will have to pass the --allow-live flag",
 					colors.Red(e.Name),
 					colors.Yellow(shop.Name),
@@ -134,24 +212,64 @@ func createCtx(newClient clientFact, conf env.Conf, e *env.Env, flags Flags, arg
 		}
 	}
 
+	if e.ThemeID != "" && !flags.SkipThemeCheck {
+		if err := checkThemeExists(themes, e); err != nil {
+			return &Ctx{}, err
+		}
+	}
+
 	return &Ctx{
-		Shop:     shop,
-		Conf:     &conf,
-		Client:   client,
-		Env:      e,
-		Flags:    flags,
-		Args:     args,
-		progress: progress,
-		Log:      colors.ColorStdOut,
-		ErrLog:   colors.ColorStdErr,
-		summary:  cmdSummary{},
+		Shop:       shop,
+		Conf:       &conf,
+		Client:     client,
+		Env:        e,
+		Flags:      flags,
+		Args:       args,
+		progress:   progress,
+		Log:        stdLog(flags),
+		ErrLog:     errLog(flags),
+		SummaryLog: summaryLog(flags),
+		Out:        os.Stdout,
+		summary:    cmdSummary{started: time.Now()},
 	}, nil
 }
 
+// resolveLiveTheme will replace the live theme id with the id of the published theme
+func resolveLiveTheme(themes []shopify.Theme, e *env.Env) error {
+	for _, theme := range themes {
+		if theme.Role == "main" {
+			e.ThemeID = fmt.Sprintf("%v", theme.ID)
+			e.ThemeIDs = []string{e.ThemeID}
+			return nil
+		}
+	}
+	return fmt.Errorf("no live theme found in shop %s", e.Domain)
+}
+
+// checkThemeExists makes sure the configured theme id is one of the themes in the
+// shop so that a typo does not surface later as a 404 on every asset.
+func checkThemeExists(themes []shopify.Theme, e *env.Env) error {
+	ids := []string{}
+	for _, theme := range themes {
+		id := fmt.Sprintf("%v", theme.ID)
+		if id == e.ThemeID {
+			return nil
+		}
+		ids = append(ids, id)
+	}
+	return fmt.Errorf("theme %s not found in shop %s, available theme ids: %s", e.ThemeID, e.Domain, strings.Join(ids, ", "))
+}
+
 // StartProgress will create a new progress bar for the running context with the
-// total amount of tasks as the count
+// total amount of tasks as the count. If stdout is not a terminal then progress
+// will be logged periodically instead.
 func (ctx *Ctx) StartProgress(count int) {
-	if !ctx.Flags.Verbose && ctx.progress != nil {
+	if ctx.Flags.Verbose || ctx.Flags.Quiet || ctx.Flags.JSON || ctx.Flags.NoProgress {
+		return
+	} else if !isTerminal() {
+		atomic.StoreInt32(&ctx.counter.total, int32(count))
+		ctx.counter.lastReport = time.Now()
+	} else if ctx.progress != nil {
 		ctx.Bar = ctx.progress.AddBar(
 			int64(count),
 			mpb.PrependDecorators(decor.Name(fmt.Sprintf("[%s] ", ctx.Env.Name)), decor.Counters(0, "%d|%d")),
@@ -170,15 +288,83 @@ func (ctx *Ctx) Err(msg string, inter ...interface{}) {
 	}
 }
 
+// Result will output the result of a single file operation as a line of json when
+// the --json flag is set. It is the shopify.ResultHandler for the requests made
+// for this context.
+func (ctx *Ctx) Result(event shopify.AssetEvent) {
+	if ctx.noResults || !ctx.Flags.JSON || ctx.Out == nil {
+		return
+	}
+
+	result := fileResult{
+		File:        event.Asset.Key,
+		Environment: ctx.Env.Name,
+		Event:       event.Event.String(),
+		Status:      "ok",
+		Host:        ctx.Env.Domain,
+	}
+	if event.Err != nil {
+		result.Status = "error"
+		result.Error = event.Err.Error()
+	}
+
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	json.NewEncoder(ctx.Out).Encode(result)
+}
+
 // DoneTask will mark one unit of work complete. If the context has a progress bar
 // then it will increment it.
 func (ctx *Ctx) DoneTask(op file.Op) {
 	if !ctx.Flags.Verbose && ctx.Bar != nil {
 		ctx.Bar.Increment()
+	} else if total := atomic.LoadInt32(&ctx.counter.total); total > 0 {
+		done := atomic.AddInt32(&ctx.counter.done, 1)
+		ctx.mu.Lock()
+		if done == total || time.Since(ctx.counter.lastReport) >= progressInterval {
+			ctx.counter.lastReport = time.Now()
+			ctx.Log.Printf("[%s] %d/%d files", colors.Green(ctx.Env.Name), done, total)
+		}
+		ctx.mu.Unlock()
 	}
 	ctx.summary.completeOp(op)
 }
 
+// AddBytes records the size of a file that was transferred for the summary
+func (ctx *Ctx) AddBytes(n int) {
+	ctx.summary.addBytes(n)
+}
+
+// HasErrors will return true if any errors were reported while running
+func (ctx *Ctx) HasErrors() bool {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	return len(ctx.summary.errors) > 0
+}
+
+// WithDirectory returns a new context for the same environment that reads and
+// writes files in dir instead of the project directory, like when downloading a
+// backup. It has its own summary so that its errors are checked separately, and
+// its file operations are not reported as results or json output.
+func (ctx *Ctx) WithDirectory(dir string) *Ctx {
+	e := *ctx.Env
+	e.Directory = dir
+	return &Ctx{
+		Shop:       ctx.Shop,
+		Conf:       ctx.Conf,
+		Client:     ctx.Client,
+		Flags:      ctx.Flags,
+		Env:        &e,
+		Args:       ctx.Args,
+		Log:        ctx.Log,
+		ErrLog:     ctx.ErrLog,
+		SummaryLog: ctx.SummaryLog,
+		Out:        ctx.Out,
+		summary:    cmdSummary{started: time.Now()},
+		noResults:  true,
+	}
+}
+
 // DisableSummary will ensure that the file operation summary will not output at
 // the end of the operation
 func (ctx *Ctx) DisableSummary() {
@@ -187,53 +373,79 @@ func (ctx *Ctx) DisableSummary() {
 
 func generateContexts(newClient clientFact, progress *mpb.Progress, flags Flags, args []string) ([]*Ctx, error) {
 	ctxs := []*Ctx{}
-	flagEnv := getFlagEnv(flags)
 
-	if err := env.SourceVariables(flags.VariableFilePath); err != nil {
+	if err := configureLogging(flags); err != nil {
 		return ctxs, err
 	}
 
-	config, err := env.Load(flags.ConfigPath)
-	if err != nil && os.IsNotExist(err) {
-		colors.ColorStdOut.Printf(
-			"[%s] Could not find config file at %v",
-			colors.Yellow("warn"),
-			colors.Yellow(flags.ConfigPath),
-		)
-	} else if err != nil {
+	config, envs, err := resolveEnvironments(flags)
+	if err != nil {
 		return ctxs, err
 	}
 
-	for _, name := range expandEnvironments(flags, config.Envs) {
-		e, err := config.Get(name, flagEnv)
-		if err != nil && err != env.ErrEnvDoesNotExist {
-			return ctxs, err
-		} else if e == nil {
-			if e, err = config.Set(name, flagEnv); err != nil {
-				return ctxs, err
+	themeEnvs := []*env.Env{}
+	for _, e := range envs {
+		themeEnvs = append(themeEnvs, expandThemeIDs(e)...)
+	}
+
+	// contexts are created concurrently because each one makes api calls, the
+	// first failure cancels any that have not started yet.
+	results := make([]*Ctx, len(themeEnvs))
+	group, groupCtx := errgroup.WithContext(context.Background())
+	limit := make(chan struct{}, contextConcurrency)
+	for i, themeEnv := range themeEnvs {
+		i, themeEnv := i, themeEnv
+		group.Go(func() error {
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			select {
+			case <-groupCtx.Done():
+				return groupCtx.Err()
+			default:
 			}
-		}
+			ctx, err := createCtx(newClient, config, themeEnv, flags, args, progress)
+			results[i] = ctx
+			return err
+		})
+	}
 
-		ctx, err := createCtx(newClient, config, e, flags, args, progress)
-		if err != nil {
-			return ctxs, err
-		}
+	if err := group.Wait(); err != nil {
+		return ctxs, err
+	}
 
-		ctxs = append(ctxs, ctx)
+	return results, nil
+}
+
+// expandThemeIDs will split an environment with multiple theme ids into one
+// environment per theme so that each theme gets its own client.
+func expandThemeIDs(e *env.Env) []*env.Env {
+	if len(e.ThemeIDs) <= 1 {
+		return []*env.Env{e}
 	}
 
-	return ctxs, nil
+	envs := []*env.Env{}
+	for _, id := range e.ThemeIDs {
+		themeEnv := *e
+		themeEnv.Name = fmt.Sprintf("%s:%s", e.Name, id)
+		themeEnv.ThemeID = id
+		themeEnv.ThemeIDs = []string{id}
+		envs = append(envs, &themeEnv)
+	}
+	return envs
 }
 
 func getFlagEnv(flags Flags) env.Env {
 	flagEnv := env.Env{
-		Directory: flags.Directory,
-		Password:  flags.Password,
-		ThemeID:   flags.ThemeID,
-		Domain:    flags.Domain,
-		Proxy:     flags.Proxy,
-		Timeout:   flags.Timeout,
-		Notify:    flags.Notify,
+		Directory:      flags.Directory,
+		Password:       flags.Password,
+		AccessToken:    flags.AccessToken,
+		ThemeID:        flags.ThemeID,
+		Domain:         flags.Domain,
+		Proxy:          flags.Proxy,
+		Timeout:        flags.Timeout,
+		ConnectTimeout: flags.ConnectTimeout,
+		Notify:         flags.Notify,
+		Debounce:       flags.Debounce,
 	}
 
 	if !flags.DisableIgnore {
@@ -269,6 +481,78 @@ func expandEnvironments(flags Flags, confEnvs map[string]*env.Env) []string {
 	return envs
 }
 
+// ResolveEnvironments will merge the config file, environment variables and flags
+// for every environment selected by the flags without connecting to shopify.
+func ResolveEnvironments(flags Flags) ([]*env.Env, error) {
+	_, envs, err := resolveEnvironments(flags)
+	return envs, err
+}
+
+func resolveEnvironments(flags Flags) (env.Conf, []*env.Env, error) {
+	envs := []*env.Env{}
+	config, err := loadConfig(flags, true)
+	if err != nil {
+		return config, envs, err
+	}
+
+	for _, name := range expandEnvironments(flags, config.Envs) {
+		e, err := resolveEnvironment(&config, name, flags)
+		if err != nil {
+			return config, envs, err
+		}
+		envs = append(envs, e)
+	}
+
+	return config, envs, nil
+}
+
+// loadConfig sources the variables and loads the config files from the flags. A
+// missing config file is not an error, it is only warned about if warnMissing is
+// set.
+func loadConfig(flags Flags, warnMissing bool) (env.Conf, error) {
+	if err := env.SourceVariables(flags.VariableFilePath); err != nil {
+		return env.Conf{}, err
+	} else if err := env.SetVariables(flags.Variables); err != nil {
+		return env.Conf{}, err
+	}
+	env.SetProxy(flags.Proxy)
+
+	config, err := env.LoadAll(configPaths(flags)...)
+	if err != nil && os.IsNotExist(err) {
+		if !warnMissing {
+			return config, nil
+		}
+		stdLog(flags).Printf(
+			"[%s] Could not find config file at %v",
+			colors.Yellow("warn"),
+			colors.Yellow(flags.ConfigPath),
+		)
+	} else if err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// configPaths returns the config files to load in order. The first file is the
+// project config, any others are merged on top of it.
+func configPaths(flags Flags) []string {
+	if len(flags.ConfigPaths) > 0 {
+		return flags.ConfigPaths
+	}
+	return []string{flags.ConfigPath}
+}
+
+func resolveEnvironment(config *env.Conf, name string, flags Flags) (*env.Env, error) {
+	flagEnv := getFlagEnv(flags)
+	e, err := config.Get(name, flagEnv)
+	if err != nil && err != env.ErrEnvDoesNotExist {
+		return nil, err
+	} else if e == nil {
+		return config.Set(name, flagEnv)
+	}
+	return e, nil
+}
+
 // ForEachClient will generate a command context for all the available environments
 // and run a command in each of those contexts
 func ForEachClient(flags Flags, args []string, handler func(*Ctx) error) error {
@@ -281,17 +565,24 @@ func forEachClient(newClient clientFact, flags Flags, args []string, handler fun
 	if err != nil {
 		return err
 	}
-	var handlerGroup errgroup.Group
-	for _, ctx := range ctxs {
-		ctx := ctx
-		handlerGroup.Go(func() error { return handler(ctx) })
-	}
-	err = handlerGroup.Wait()
+	var handlerGroup sync.WaitGroup
+	handlerErrs := make([]error, len(ctxs))
+	for i, ctx := range ctxs {
+		handlerGroup.Add(1)
+		go func(i int, ctx *Ctx) {
+			defer handlerGroup.Done()
+			handlerErrs[i] = handler(ctx)
+		}(i, ctx)
+	}
+	handlerGroup.Wait()
+	err = combineErrors(handlerErrs)
 	if err == nil {
 		progressBarGroup.Wait()
 	}
-	if err == ErrReload {
-		return forEachClient(newClient, flags, args, handler)
+	for _, handlerErr := range handlerErrs {
+		if handlerErr == ErrReload {
+			return forEachClient(newClient, flags, args, handler)
+		}
 	}
 	hasErrors := false
 	for _, ctx := range ctxs {
@@ -304,6 +595,23 @@ func forEachClient(newClient clientFact, flags Flags, args []string, handler fun
 	return err
 }
 
+// combineErrors will reduce the errors from all the environments that ran into
+// a single error so that none of them are lost when reporting back to the user.
+func combineErrors(errs []error) error {
+	msgs := []string{}
+	var last error
+	for _, err := range errs {
+		if err != nil {
+			last = err
+			msgs = append(msgs, err.Error())
+		}
+	}
+	if len(msgs) <= 1 {
+		return last
+	}
+	return errors.New(strings.Join(msgs, "\n"))
+}
+
 // ForSingleClient will generate a command context for all the available environments,
 // and run a command for the first context. If more than one environment was specified,
 // then an error will be returned.
@@ -317,7 +625,7 @@ func forSingleClient(newClient clientFact, flags Flags, args []string, handler f
 	if err != nil {
 		return err
 	} else if len(ctxs) > 1 {
-		return fmt.Errorf("more than one environment specified for a single environment command")
+		return fmt.Errorf("more than one environment or theme id specified for a single environment command")
 	}
 	err = handler(ctxs[0])
 	if err == nil {
@@ -342,14 +650,13 @@ func ForDefaultClient(flags Flags, args []string, handler func(*Ctx) error) erro
 func forDefaultClient(newClient clientFact, flags Flags, args []string, handler func(*Ctx) error) error {
 	progressBarGroup := mpb.New(nil)
 
-	if err := env.SourceVariables(flags.VariableFilePath); err != nil {
+	if err := configureLogging(flags); err != nil {
 		return err
 	}
 
-	config, err := env.Load(flags.ConfigPath)
-	if err != nil && os.IsNotExist(err) {
-		config = env.New(flags.ConfigPath)
-	} else if err != nil {
+	// a missing project config is fine, the environment can come from the flags
+	config, err := loadConfig(flags, false)
+	if err != nil {
 		return err
 	}
 
@@ -367,6 +674,10 @@ func forDefaultClient(newClient clientFact, flags Flags, args []string, handler
 		}
 	}
 
+	if len(e.ThemeIDs) > 1 {
+		return fmt.Errorf("[%s] more than one theme id specified for a single theme command, please use --themeid to pick one", envName)
+	}
+
 	ctx, err := createCtx(newClient, config, e, flags, args, progressBarGroup)
 	if err != nil {
 		return err
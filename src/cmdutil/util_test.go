@@ -3,8 +3,12 @@ package cmdutil
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/vbauerster/mpb"
@@ -58,18 +62,76 @@ func TestCreateCtx(t *testing.T) {
 	_, err = createCtx(factory, env.Conf{}, e, Flags{DisableIgnore: true}, []string{}, nil)
 	assert.Equal(t, ErrLiveTheme, err)
 	assert.Equal(t, e.ThemeID, "1234")
+
+	e = &env.Env{ThemeID: "999", Domain: "shop.myshopify.com"}
+	client = new(mocks.ShopifyClient)
+	client.On("GetShop").Return(shopify.Shop{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 65443, Role: "unpublished"}, {ID: 1234, Role: "main"}}, nil)
+	_, err = createCtx(factory, env.Conf{}, e, Flags{}, []string{}, nil)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "theme 999 not found in shop shop.myshopify.com, available theme ids: 65443, 1234", err.Error())
+	}
+	_, err = createCtx(factory, env.Conf{}, e, Flags{SkipThemeCheck: true}, []string{}, nil)
+	assert.Nil(t, err)
+
+	e = &env.Env{ThemeID: "live", Domain: "shop.myshopify.com"}
+	client = new(mocks.ShopifyClient)
+	client.On("GetShop").Return(shopify.Shop{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 65443, Role: "unpublished"}, {ID: 1234, Role: "main"}}, nil)
+	_, err = createCtx(factory, env.Conf{}, e, Flags{AllowLive: true, DisableIgnore: true}, []string{}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "1234", e.ThemeID)
+
+	e = &env.Env{ThemeID: "live", Domain: "shop.myshopify.com"}
+	client = new(mocks.ShopifyClient)
+	client.On("GetShop").Return(shopify.Shop{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 65443, Role: "unpublished"}}, nil)
+	_, err = createCtx(factory, env.Conf{}, e, Flags{AllowLive: true}, []string{}, nil)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "no live theme found in shop shop.myshopify.com", err.Error())
+	}
+}
+
+func stubTerminal(tty bool) func() {
+	original := isTerminal
+	isTerminal = func() bool { return tty }
+	return func() { isTerminal = original }
 }
 
 func TestCtx_StartProgress(t *testing.T) {
+	defer stubTerminal(true)()
 	ctx := Ctx{Env: &env.Env{}, Flags: Flags{Verbose: true}, progress: mpb.New(nil)}
 	ctx.StartProgress(6)
 	assert.Nil(t, ctx.Bar)
 	ctx.Flags.Verbose = false
+	ctx.Flags.NoProgress = true
+	ctx.StartProgress(6)
+	assert.Nil(t, ctx.Bar)
+	ctx.Flags.NoProgress = false
 	ctx.StartProgress(6)
 	assert.NotNil(t, ctx.Bar)
 }
 
+func TestCtx_ProgressWithoutTerminal(t *testing.T) {
+	defer stubTerminal(false)()
+	stdOut := bytes.NewBufferString("")
+	ctx := Ctx{Env: &env.Env{Name: "development"}, Flags: Flags{}, progress: mpb.New(nil), Log: log.New(stdOut, "", 0)}
+	ctx.StartProgress(3)
+	assert.Nil(t, ctx.Bar)
+
+	ctx.DoneTask(file.Update)
+	assert.Equal(t, "", stdOut.String())
+
+	ctx.counter.lastReport = time.Now().Add(-progressInterval)
+	ctx.DoneTask(file.Update)
+	assert.Contains(t, stdOut.String(), "2/3 files")
+
+	ctx.DoneTask(file.Update)
+	assert.Contains(t, stdOut.String(), "3/3 files")
+}
+
 func TestCtx_Err(t *testing.T) {
+	defer stubTerminal(true)()
 	stdErr := bytes.NewBufferString("")
 	ctx := Ctx{Env: &env.Env{}, Flags: Flags{}, progress: mpb.New(nil), ErrLog: log.New(stdErr, "", 0)}
 
@@ -85,6 +147,7 @@ func TestCtx_Err(t *testing.T) {
 }
 
 func TestCtx_DoneTask(t *testing.T) {
+	defer stubTerminal(true)()
 	ctx := Ctx{Env: &env.Env{}, Flags: Flags{}, progress: mpb.New(nil)}
 	assert.NotPanics(t, func() {
 		ctx.DoneTask(file.Update)
@@ -96,15 +159,34 @@ func TestCtx_DoneTask(t *testing.T) {
 	assert.Equal(t, ctx.Bar.Current(), int64(1))
 }
 
+func TestCtx_Result(t *testing.T) {
+	stdOut := bytes.NewBufferString("")
+	ctx := Ctx{Env: &env.Env{Name: "development", Domain: "shop.myshopify.com"}, Flags: Flags{}, Out: stdOut}
+
+	ctx.Result(shopify.AssetEvent{Event: file.Update, Asset: shopify.Asset{Key: "templates/index.liquid"}})
+	assert.Equal(t, "", stdOut.String())
+
+	ctx.Flags.JSON = true
+	ctx.Result(shopify.AssetEvent{Event: file.Update, Asset: shopify.Asset{Key: "templates/index.liquid"}})
+	ctx.Result(shopify.AssetEvent{Event: file.Remove, Asset: shopify.Asset{Key: "assets/app.js"}, Err: fmt.Errorf("not found")})
+	assert.Equal(t, `{"file":"templates/index.liquid","environment":"development","event":"update","status":"ok","host":"shop.myshopify.com"}
+{"file":"assets/app.js","environment":"development","event":"remove","status":"error","error":"not found","host":"shop.myshopify.com"}
+`, stdOut.String())
+
+	ctx.progress = mpb.New(nil)
+	ctx.StartProgress(6)
+	assert.Nil(t, ctx.Bar)
+}
+
 func TestGenerateContexts(t *testing.T) {
 	factory := func(*env.Env) (shopifyClient, error) { return nil, nil }
 	_, err := generateContexts(factory, nil, Flags{Environments: []string{"development"}}, []string{})
-	assert.EqualError(t, err, "invalid environment [development]: (missing theme_id,missing store domain,missing password)")
+	assert.EqualError(t, err, "invalid environment [development]: (missing theme_id,missing store domain,missing password or access_token)")
 
 	client := new(mocks.ShopifyClient)
 	factory = func(*env.Env) (shopifyClient, error) { return client, nil }
 	client.On("GetShop").Return(shopify.Shop{}, nil)
-	client.On("Themes").Return([]shopify.Theme{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 123}}, nil)
 	ctxs, err := generateContexts(factory, nil, Flags{Environments: []string{"development"}, ConfigPath: "_testdata/config.yml"}, []string{})
 	assert.Nil(t, err)
 	assert.Equal(t, len(ctxs), 1)
@@ -112,23 +194,49 @@ func TestGenerateContexts(t *testing.T) {
 	client = new(mocks.ShopifyClient)
 	factory = func(*env.Env) (shopifyClient, error) { return client, nil }
 	_, err = generateContexts(factory, nil, Flags{ConfigPath: "_testdata/config.yml", Environments: []string{"nope"}}, []string{})
-	assert.EqualError(t, err, "invalid environment [nope]: (missing theme_id,missing store domain,missing password)")
+	assert.EqualError(t, err, "invalid environment [nope]: (missing theme_id,missing store domain,missing password or access_token)")
 
 	client = new(mocks.ShopifyClient)
 	factory = func(*env.Env) (shopifyClient, error) { return client, fmt.Errorf("not today") }
 	_, err = generateContexts(factory, nil, Flags{Environments: []string{"development"}, ConfigPath: "_testdata/config.yml"}, []string{})
 	assert.EqualError(t, err, "not today")
+
+	client = new(mocks.ShopifyClient)
+	factory = func(*env.Env) (shopifyClient, error) { return client, nil }
+	client.On("GetShop").Return(shopify.Shop{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 123}}, nil)
+	ctxs, err = generateContexts(factory, nil, Flags{Environments: []string{"production", "development"}, ConfigPath: "_testdata/config.yml"}, []string{})
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(ctxs)) {
+		assert.Equal(t, "production", ctxs[0].Env.Name)
+		assert.Equal(t, "development", ctxs[1].Env.Name)
+	}
+}
+
+func TestExpandThemeIDs(t *testing.T) {
+	e := &env.Env{Name: "development", ThemeID: "123", ThemeIDs: []string{"123"}}
+	assert.Equal(t, []*env.Env{e}, expandThemeIDs(e))
+
+	e = &env.Env{Name: "development", ThemeID: "123", ThemeIDs: []string{"123", "456"}, Domain: "shop.myshopify.com"}
+	envs := expandThemeIDs(e)
+	if assert.Equal(t, 2, len(envs)) {
+		assert.Equal(t, env.Env{Name: "development:123", ThemeID: "123", ThemeIDs: []string{"123"}, Domain: "shop.myshopify.com"}, *envs[0])
+		assert.Equal(t, env.Env{Name: "development:456", ThemeID: "456", ThemeIDs: []string{"456"}, Domain: "shop.myshopify.com"}, *envs[1])
+	}
+	assert.Equal(t, "123", e.ThemeID)
 }
 
 func TestGetFlagEnv(t *testing.T) {
 	flags := Flags{
 		Directory:    "d",
 		Password:     "p",
+		AccessToken:  "a",
 		ThemeID:      "t",
 		Domain:       "o",
 		Proxy:        "r",
 		Timeout:      1,
 		Notify:       "n",
+		Debounce:     2,
 		IgnoredFiles: []string{"i"},
 		Ignores:      []string{"c"},
 	}
@@ -136,11 +244,13 @@ func TestGetFlagEnv(t *testing.T) {
 	e := env.Env{
 		Directory:    "d",
 		Password:     "p",
+		AccessToken:  "a",
 		ThemeID:      "t",
 		Domain:       "o",
 		Proxy:        "r",
 		Timeout:      1,
 		Notify:       "n",
+		Debounce:     2,
 		IgnoredFiles: []string{"i"},
 		Ignores:      []string{"c"},
 	}
@@ -151,13 +261,15 @@ func TestGetFlagEnv(t *testing.T) {
 	assert.NotEqual(t, e, getFlagEnv(flags))
 
 	e = env.Env{
-		Directory: "d",
-		Password:  "p",
-		ThemeID:   "t",
-		Domain:    "o",
-		Proxy:     "r",
-		Timeout:   1,
-		Notify:    "n",
+		Directory:   "d",
+		Password:    "p",
+		AccessToken: "a",
+		ThemeID:     "t",
+		Domain:      "o",
+		Proxy:       "r",
+		Timeout:     1,
+		Notify:      "n",
+		Debounce:    2,
 	}
 
 	assert.Equal(t, e, getFlagEnv(flags))
@@ -197,14 +309,14 @@ func TestForEachClient(t *testing.T) {
 	client := new(mocks.ShopifyClient)
 	factory := func(*env.Env) (shopifyClient, error) { return client, nil }
 	client.On("GetShop").Return(shopify.Shop{}, nil)
-	client.On("Themes").Return([]shopify.Theme{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 123}}, nil)
 	err := forEachClient(factory, Flags{ConfigPath: "_testdata/config.yml"}, []string{}, safeHandler)
 	assert.Nil(t, err)
 
 	client = new(mocks.ShopifyClient)
 	factory = func(*env.Env) (shopifyClient, error) { return client, nil }
 	client.On("GetShop").Return(shopify.Shop{}, nil)
-	client.On("Themes").Return([]shopify.Theme{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 123}}, nil)
 	err = forEachClient(factory, Flags{Environments: []string{"development"}, ConfigPath: "_testdata/config.yml"}, []string{}, errHandler)
 	assert.EqualError(t, err, gandalfErr.Error())
 
@@ -219,7 +331,7 @@ func TestForEachClient(t *testing.T) {
 	client = new(mocks.ShopifyClient)
 	factory = func(*env.Env) (shopifyClient, error) { return client, nil }
 	client.On("GetShop").Return(shopify.Shop{}, nil)
-	client.On("Themes").Return([]shopify.Theme{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 123}}, nil)
 	err = forEachClient(factory, Flags{Environments: []string{"development"}, ConfigPath: "_testdata/config.yml"}, []string{}, handler)
 	assert.EqualError(t, err, "nope not at all")
 	assert.Equal(t, 2, count)
@@ -235,10 +347,28 @@ func TestForEachClient(t *testing.T) {
 	client = new(mocks.ShopifyClient)
 	factory = func(*env.Env) (shopifyClient, error) { return client, nil }
 	client.On("GetShop").Return(shopify.Shop{}, nil)
-	client.On("Themes").Return([]shopify.Theme{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 123}}, nil)
 	err = forEachClient(factory, Flags{Environments: []string{"development"}, ConfigPath: "_testdata/config.yml"}, []string{}, handler)
 	assert.Equal(t, ErrDuringRuntime, err)
 	assert.Contains(t, stdErr.String(), "Errors encountered: ")
+
+	envErrHandler := func(ctx *Ctx) error { return fmt.Errorf("%s failed", ctx.Env.Name) }
+	client = new(mocks.ShopifyClient)
+	factory = func(*env.Env) (shopifyClient, error) { return client, nil }
+	client.On("GetShop").Return(shopify.Shop{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 123}}, nil)
+	err = forEachClient(factory, Flags{Environments: []string{"*"}, ConfigPath: "_testdata/config.yml"}, []string{}, envErrHandler)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "development failed")
+		assert.Contains(t, err.Error(), "production failed")
+	}
+}
+
+func TestCombineErrors(t *testing.T) {
+	assert.Nil(t, combineErrors([]error{nil, nil}))
+	gandalfErr := fmt.Errorf("you shall not pass")
+	assert.Equal(t, gandalfErr, combineErrors([]error{nil, gandalfErr}))
+	assert.EqualError(t, combineErrors([]error{fmt.Errorf("one"), nil, fmt.Errorf("two")}), "one\ntwo")
 }
 
 func TestForSingleClient(t *testing.T) {
@@ -249,21 +379,21 @@ func TestForSingleClient(t *testing.T) {
 	client := new(mocks.ShopifyClient)
 	factory := func(*env.Env) (shopifyClient, error) { return client, nil }
 	client.On("GetShop").Return(shopify.Shop{}, nil)
-	client.On("Themes").Return([]shopify.Theme{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 123}}, nil)
 	err := forSingleClient(factory, Flags{Environments: []string{"development"}, ConfigPath: "_testdata/config.yml"}, []string{}, safeHandler)
 	assert.Nil(t, err)
 
 	client = new(mocks.ShopifyClient)
 	factory = func(*env.Env) (shopifyClient, error) { return client, nil }
 	client.On("GetShop").Return(shopify.Shop{}, nil)
-	client.On("Themes").Return([]shopify.Theme{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 123}}, nil)
 	err = forSingleClient(factory, Flags{ConfigPath: "_testdata/config.yml", Environments: []string{"*"}}, []string{}, safeHandler)
-	assert.EqualError(t, err, "more than one environment specified for a single environment command")
+	assert.EqualError(t, err, "more than one environment or theme id specified for a single environment command")
 
 	client = new(mocks.ShopifyClient)
 	factory = func(*env.Env) (shopifyClient, error) { return client, nil }
 	client.On("GetShop").Return(shopify.Shop{}, nil)
-	client.On("Themes").Return([]shopify.Theme{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 123}}, nil)
 	err = forSingleClient(factory, Flags{Environments: []string{"development"}, ConfigPath: "_testdata/config.yml"}, []string{}, errHandler)
 	assert.EqualError(t, err, gandalfErr.Error())
 
@@ -278,7 +408,7 @@ func TestForSingleClient(t *testing.T) {
 	client = new(mocks.ShopifyClient)
 	factory = func(*env.Env) (shopifyClient, error) { return client, nil }
 	client.On("GetShop").Return(shopify.Shop{}, nil)
-	client.On("Themes").Return([]shopify.Theme{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 123}}, nil)
 	err = forSingleClient(factory, Flags{Environments: []string{"development"}, ConfigPath: "_testdata/config.yml"}, []string{}, handler)
 	assert.EqualError(t, err, "nope not at all")
 	assert.Equal(t, 2, count)
@@ -294,7 +424,7 @@ func TestForSingleClient(t *testing.T) {
 	client = new(mocks.ShopifyClient)
 	factory = func(*env.Env) (shopifyClient, error) { return client, nil }
 	client.On("GetShop").Return(shopify.Shop{}, nil)
-	client.On("Themes").Return([]shopify.Theme{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 123}}, nil)
 	err = forSingleClient(factory, Flags{Environments: []string{"development"}, ConfigPath: "_testdata/config.yml"}, []string{}, handler)
 	assert.Equal(t, ErrDuringRuntime, err)
 	assert.Contains(t, stdErr.String(), "Errors encountered")
@@ -307,19 +437,19 @@ func TestForDefaultClient(t *testing.T) {
 
 	factory := func(*env.Env) (shopifyClient, error) { return nil, nil }
 	err := forDefaultClient(factory, Flags{}, []string{}, safeHandler)
-	assert.EqualError(t, err, "invalid environment [development]: (missing theme_id,missing store domain,missing password)")
+	assert.EqualError(t, err, "invalid environment [development]: (missing theme_id,missing store domain,missing password or access_token)")
 
 	client := new(mocks.ShopifyClient)
 	factory = func(*env.Env) (shopifyClient, error) { return client, nil }
 	client.On("GetShop").Return(shopify.Shop{}, nil)
-	client.On("Themes").Return([]shopify.Theme{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 123}}, nil)
 	err = forDefaultClient(factory, Flags{ConfigPath: "_testdata/config.yml"}, []string{}, safeHandler)
 	assert.Nil(t, err)
 
 	client = new(mocks.ShopifyClient)
 	factory = func(*env.Env) (shopifyClient, error) { return client, nil }
 	client.On("GetShop").Return(shopify.Shop{}, nil)
-	client.On("Themes").Return([]shopify.Theme{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 123}}, nil)
 	err = forDefaultClient(factory, Flags{Domain: "shop.myshopify.com", Password: "123", ThemeID: "123"}, []string{}, safeHandler)
 	assert.Nil(t, err)
 
@@ -331,7 +461,7 @@ func TestForDefaultClient(t *testing.T) {
 	client = new(mocks.ShopifyClient)
 	factory = func(*env.Env) (shopifyClient, error) { return client, nil }
 	client.On("GetShop").Return(shopify.Shop{}, nil)
-	client.On("Themes").Return([]shopify.Theme{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 123}}, nil)
 	err = forDefaultClient(factory, Flags{Domain: "shop.myshopify.com", Password: "123", ThemeID: "123"}, []string{}, errHandler)
 	assert.EqualError(t, err, gandalfErr.Error())
 
@@ -346,8 +476,43 @@ func TestForDefaultClient(t *testing.T) {
 	client = new(mocks.ShopifyClient)
 	factory = func(*env.Env) (shopifyClient, error) { return client, nil }
 	client.On("GetShop").Return(shopify.Shop{}, nil)
-	client.On("Themes").Return([]shopify.Theme{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 123}}, nil)
 	forDefaultClient(factory, Flags{ConfigPath: "_testdata/config.yml"}, []string{}, handler)
 	assert.Equal(t, gandalfErr, err)
 	assert.Contains(t, stdErr.String(), "Errors encountered: ")
+
+	dir, err := ioutil.TempDir("", "themekit-config")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	configPath := filepath.Join(dir, "config.yml")
+	assert.Nil(t, ioutil.WriteFile(configPath, []byte("development:\n  store: shop.myshopify.com\n  password: abc123\n  theme_ids: [123, 456]\n"), 0644))
+	err = forDefaultClient(factory, Flags{ConfigPath: configPath}, []string{}, safeHandler)
+	assert.EqualError(t, err, "[development] more than one theme id specified for a single theme command, please use --themeid to pick one")
+
+	var themeIDs []string
+	err = forDefaultClient(factory, Flags{ConfigPath: configPath, ThemeID: "123"}, []string{}, func(ctx *Ctx) error {
+		themeIDs = ctx.Env.ThemeIDs
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"123"}, themeIDs)
+}
+
+func TestResolveEnvironments(t *testing.T) {
+	envs, err := ResolveEnvironments(Flags{Environments: []string{"development"}, ConfigPath: "_testdata/config.yml"})
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(envs)) {
+		assert.Equal(t, "development", envs[0].Name)
+		assert.Equal(t, "123", envs[0].ThemeID)
+	}
+
+	_, err = ResolveEnvironments(Flags{Environments: []string{"development"}, ConfigPath: "_testdata/invalid_config.yml"})
+	assert.NotNil(t, err)
+
+	envs, err = ResolveEnvironments(Flags{Environments: []string{"development"}, ConfigPaths: []string{"_testdata/config.yml", "_testdata/config.local.yml"}})
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(envs)) {
+		assert.Equal(t, "456", envs[0].ThemeID)
+		assert.Equal(t, "abracadabra", envs[0].Password)
+	}
 }
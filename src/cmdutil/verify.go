@@ -0,0 +1,42 @@
+package cmdutil
+
+// EnvStatus is the result of verifying a single environment, Err is nil if the
+// environment is valid and could connect to shopify.
+type EnvStatus struct {
+	Name string
+	Err  error
+}
+
+// VerifyEnvironments will validate every selected environment and make sure that
+// it can authenticate with shopify. Unlike running a command, every environment
+// is checked even if an earlier one fails.
+func VerifyEnvironments(flags Flags) ([]EnvStatus, error) {
+	return verifyEnvironments(shopifyThemeClientFactory, flags)
+}
+
+func verifyEnvironments(newClient clientFact, flags Flags) ([]EnvStatus, error) {
+	statuses := []EnvStatus{}
+	if err := configureLogging(flags); err != nil {
+		return statuses, err
+	}
+
+	config, err := loadConfig(flags, true)
+	if err != nil {
+		return statuses, err
+	}
+
+	for _, name := range expandEnvironments(flags, config.Envs) {
+		e, err := resolveEnvironment(&config, name, flags)
+		if err != nil {
+			statuses = append(statuses, EnvStatus{Name: name, Err: err})
+			continue
+		}
+
+		for _, themeEnv := range expandThemeIDs(e) {
+			_, err := createCtx(newClient, config, themeEnv, flags, []string{}, nil)
+			statuses = append(statuses, EnvStatus{Name: themeEnv.Name, Err: err})
+		}
+	}
+
+	return statuses, nil
+}
@@ -0,0 +1,39 @@
+package cmdutil
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Shopify/themekit/src/cmdutil/_mocks"
+	"github.com/Shopify/themekit/src/env"
+	"github.com/Shopify/themekit/src/shopify"
+)
+
+func TestVerifyEnvironments(t *testing.T) {
+	client := new(mocks.ShopifyClient)
+	client.On("GetShop").Return(shopify.Shop{}, nil)
+	client.On("Themes").Return([]shopify.Theme{{ID: 123}}, nil)
+	factory := func(*env.Env) (shopifyClient, error) { return client, nil }
+
+	statuses, err := verifyEnvironments(factory, Flags{Environments: []string{"development", "nope", "production"}, ConfigPath: "_testdata/config.yml"})
+	assert.Nil(t, err)
+	if assert.Equal(t, 3, len(statuses)) {
+		assert.Equal(t, EnvStatus{Name: "development"}, statuses[0])
+		assert.Equal(t, "nope", statuses[1].Name)
+		assert.NotNil(t, statuses[1].Err)
+		assert.Equal(t, EnvStatus{Name: "production"}, statuses[2])
+	}
+
+	client = new(mocks.ShopifyClient)
+	client.On("GetShop").Return(shopify.Shop{}, fmt.Errorf("unauthorized"))
+	statuses, err = verifyEnvironments(factory, Flags{Environments: []string{"development"}, ConfigPath: "_testdata/config.yml"})
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(statuses)) {
+		assert.EqualError(t, statuses[0].Err, "unauthorized")
+	}
+
+	_, err = verifyEnvironments(factory, Flags{LogLevel: "nope"})
+	assert.NotNil(t, err)
+}
@@ -7,9 +7,15 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
 	"encoding/json"
 	"github.com/caarlos0/env"
+	"github.com/imdario/mergo"
 	"github.com/joho/godotenv"
 	"github.com/shibukawa/configdir"
 	"gopkg.in/yaml.v1"
@@ -32,13 +38,22 @@ var (
 	ErrNoEnvironmentsDefined = errors.New("no environments defined, nothing to write")
 	// ErrInvalidEnvironmentName is returned if an environment is trying to be set with a blank name
 	ErrInvalidEnvironmentName = errors.New("environment name cannot be blank")
+	// ErrMergedConfig is returned when trying to save a config that was merged from multiple files
+	ErrMergedConfig = errors.New("cannot save a config that was merged from multiple files, please use a single --config")
+	// ErrRemoteConfig is returned when trying to save a config that was fetched from a url
+	ErrRemoteConfig = errors.New("cannot save a config that was fetched from a url, please save it to a local file instead")
+	// configVariables are substituted into the config file before the process environment
+	configVariables = map[string]string{}
+	// configProxy is the proxy from the flags that remote configs are fetched through
+	configProxy string
 )
 
 // Conf is a map of configurations to their environment name.
 type Conf struct {
-	Envs  map[string]*Env
-	osEnv Env
-	path  string
+	Envs   map[string]*Env
+	osEnv  Env
+	path   string
+	merged bool
 }
 
 func init() {
@@ -57,6 +72,36 @@ func SourceVariables(flagpath string) error {
 	return nil
 }
 
+// SetVariables will parse key=value pairs that will be substituted for ${key} in
+// the config file when it is loaded. Variables that are not set fall back to the
+// process environment.
+func SetVariables(vars []string) error {
+	variables := map[string]string{}
+	for _, variable := range vars {
+		parts := strings.SplitN(variable, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return fmt.Errorf("invalid variable %v, variables must be in the form key=value", variable)
+		}
+		variables[strings.TrimSpace(parts[0])] = parts[1]
+	}
+	configVariables = variables
+	return nil
+}
+
+// SetProxy will set the proxy that remote config files are fetched through. It
+// takes precedence over THEMEKIT_PROXY like the --proxy flag does for requests to
+// shopify.
+func SetProxy(proxy string) {
+	configProxy = proxy
+}
+
+func expandVariable(key string) string {
+	if value, ok := configVariables[key]; ok {
+		return value
+	}
+	return os.Getenv(key)
+}
+
 // New will build a new blank config
 func New(configPath string) Conf {
 	conf := Conf{
@@ -72,17 +117,16 @@ func New(configPath string) Conf {
 // then unmarshal the data into conf.
 func Load(configPath string) (Conf, error) {
 	conf := New(configPath)
-	path, ext, err := searchConfigPath(configPath)
-	if err != nil {
-		return conf, err
+	proxy := configProxy
+	if proxy == "" {
+		proxy = conf.osEnv.Proxy
 	}
-
-	contents, err := ioutil.ReadFile(path)
+	contents, ext, err := readConfig(configPath, proxy)
 	if err != nil {
 		return conf, err
 	}
 
-	contents = []byte(os.ExpandEnv(string(contents)))
+	contents = []byte(os.Expand(string(contents), expandVariable))
 
 	switch ext {
 	case "yml", "yaml":
@@ -95,7 +139,114 @@ func Load(configPath string) (Conf, error) {
 		}
 	}
 
-	return conf, nil
+	return conf, conf.markExplicit(contents, ext)
+}
+
+// LoadAll will load every config file in order and merge their environments into
+// the first config. Environments defined in more than one file are merged field by
+// field with the values from the later file winning. If the first config does not
+// exist the other files are still loaded and the not exist error is returned with
+// them so that the caller can decide whether it is fatal.
+func LoadAll(configPaths ...string) (Conf, error) {
+	conf, missing := Load(configPaths[0])
+	if missing != nil && !os.IsNotExist(missing) {
+		return conf, missing
+	}
+	for _, path := range configPaths[1:] {
+		override, err := Load(path)
+		if err != nil && os.IsNotExist(err) {
+			return conf, fmt.Errorf("could not find config file at %v", path)
+		} else if err != nil {
+			return conf, err
+		}
+		if err := conf.merge(override, path); err != nil {
+			return conf, err
+		}
+	}
+	return conf, missing
+}
+
+// merge will merge the environments of a config loaded from a later file into the
+// config. An environment that is moved to a different store without its own
+// credentials is an error because the credentials of one store would be sent to
+// another.
+func (c *Conf) merge(override Conf, path string) error {
+	for name, env := range override.Envs {
+		base, exists := c.Envs[name]
+		if !exists || base == nil {
+			c.Envs[name] = env
+			continue
+		} else if env == nil {
+			continue
+		}
+
+		from, to := normalizeDomain(base.Domain), normalizeDomain(env.Domain)
+		if to != "" && from != "" && to != from && env.Password == "" && env.AccessToken == "" && env.APIKey == "" {
+			return fmt.Errorf("invalid environment [%s]: %s changes the store from %s to %s without setting a password or access_token", name, path, from, to)
+		}
+
+		merged := *env
+		mergo.Merge(&merged, base)
+		merged.restoreExplicit(*env, Env{})
+		merged.explicit = mergeExplicit(*env, *base)
+		c.Envs[name] = &merged
+	}
+	c.merged = true
+	return nil
+}
+
+// namedOSEnv will parse the environment variables scoped to a single environment
+// name. For example THEMEKIT_PRODUCTION_PASSWORD will only set the password for
+// the production environment. Values that cannot be parsed are ignored.
+func namedOSEnv(name string) Env {
+	named := Env{}
+	prefix := "THEMEKIT_" + envVarName(name) + "_"
+	value := reflect.ValueOf(&named).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		key := field.Tag.Get("env")
+		if key == "" || key == "-" {
+			continue
+		}
+		if val := os.Getenv(prefix + strings.TrimPrefix(key, "THEMEKIT_")); val != "" {
+			setEnvField(value.Field(i), field, val)
+		}
+	}
+	return named
+}
+
+func envVarName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name)
+}
+
+func setEnvField(field reflect.Value, structField reflect.StructField, val string) {
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(val)
+	case []string:
+		separator := structField.Tag.Get("envSeparator")
+		if separator == "" {
+			separator = ","
+		}
+		field.Set(reflect.ValueOf(strings.Split(val, separator)))
+	case int:
+		if i, err := strconv.Atoi(val); err == nil {
+			field.SetInt(int64(i))
+		}
+	case bool:
+		if b, err := strconv.ParseBool(val); err == nil {
+			field.SetBool(b)
+		}
+	case time.Duration:
+		if d, err := time.ParseDuration(val); err == nil {
+			field.Set(reflect.ValueOf(d))
+		}
+	}
 }
 
 // Set will set the environment value and then mixin any overrides passed in. The os
@@ -105,7 +256,7 @@ func (c *Conf) Set(name string, initial Env, overrides ...Env) (*Env, error) {
 		return nil, ErrInvalidEnvironmentName
 	}
 	var err error
-	c.Envs[name], err = newEnv(name, initial, append([]Env{c.osEnv}, overrides...)...)
+	c.Envs[name], err = newEnv(name, initial, append([]Env{namedOSEnv(name), c.osEnv}, overrides...)...)
 	return c.Envs[name], err
 }
 
@@ -118,11 +269,44 @@ func (c *Conf) Get(name string, overrides ...Env) (*Env, error) {
 	} else if env == nil {
 		return env, ErrEnvNotDefined
 	}
-	return newEnv(name, *env, append([]Env{c.osEnv}, overrides...)...)
+	initial, err := c.withBase(name, *env)
+	if err != nil {
+		return nil, err
+	}
+	return newEnv(name, initial, append([]Env{namedOSEnv(name), c.osEnv}, overrides...)...)
+}
+
+// withBase will merge the environments that an environment extends into it. The
+// values of the environment win over the values of the environments it extends.
+func (c *Conf) withBase(name string, env Env) (Env, error) {
+	chain := []string{name}
+	for base := env.Extends; base != ""; {
+		for _, seen := range chain {
+			if seen == base {
+				return env, fmt.Errorf("invalid environment [%s]: circular extends %s", name, strings.Join(append(chain, base), " -> "))
+			}
+		}
+		baseEnv, exists := c.Envs[base]
+		if !exists || baseEnv == nil {
+			return env, fmt.Errorf("invalid environment [%s]: extends %s which is not defined", name, base)
+		}
+		resolved := env
+		mergo.Merge(&env, baseEnv)
+		env.restoreExplicit(resolved, Env{})
+		env.explicit = mergeExplicit(resolved, *baseEnv)
+		chain = append(chain, base)
+		base = baseEnv.Extends
+	}
+	return env, nil
 }
 
 // Save will write out the config to a file.
 func (c Conf) Save() error {
+	if c.merged {
+		return ErrMergedConfig
+	} else if isRemoteConfig(c.path) {
+		return ErrRemoteConfig
+	}
 	f, err := c.file()
 	if err != nil {
 		return err
@@ -149,6 +333,20 @@ func (c Conf) save(w io.Writer) error {
 		if env.Timeout == Default.Timeout {
 			env.Timeout = 0
 		}
+		if env.passwordRef != nil {
+			env.Password = *env.passwordRef
+		}
+		if env.accessTokenRef != nil {
+			env.AccessToken = *env.accessTokenRef
+		}
+		if env.projectIgnores != nil {
+			if env.IgnoredFiles = withoutPatterns(env.IgnoredFiles, env.projectIgnores); len(env.IgnoredFiles) == 0 {
+				env.IgnoredFiles = nil
+			}
+		}
+		if len(env.ThemeIDs) == 1 && env.ThemeIDs[0] == env.ThemeID {
+			env.ThemeIDs = nil
+		}
 		c.Envs[name] = env
 	}
 
@@ -169,6 +367,20 @@ func (c Conf) file() (io.WriteCloser, error) {
 	return os.OpenFile(c.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
 }
 
+func readConfig(configPath, proxy string) ([]byte, string, error) {
+	if isRemoteConfig(configPath) {
+		return fetchRemoteConfig(configPath, proxy)
+	}
+
+	path, ext, err := searchConfigPath(configPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	return contents, ext, err
+}
+
 func searchConfigPath(configPath string) (string, string, error) {
 	dir := filepath.Dir(configPath)
 	filename := filepath.Base(configPath)
@@ -2,9 +2,11 @@ package env
 
 import (
 	"bytes"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -46,6 +48,95 @@ func TestLoad(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "abracadabra", conf.Envs["development"].Password)
 	assert.Equal(t, "magic.myshopify.com", conf.Envs["development"].Domain)
+
+	assert.Nil(t, SetVariables([]string{"STOREPASS=shppa=secret"}))
+	defer SetVariables(nil)
+	conf, err = Load("_testdata/projectdir/valid_config.yml")
+	assert.Nil(t, err)
+	assert.Equal(t, "shppa=secret", conf.Envs["development"].Password)
+	assert.Equal(t, "magic.myshopify.com", conf.Envs["development"].Domain)
+}
+
+func TestLoadAll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "themekit-config")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	basePath := filepath.Join(dir, "config.yml")
+	localPath := filepath.Join(dir, "config.local.yml")
+	otherStorePath := filepath.Join(dir, "config.other.yml")
+	assert.Nil(t, ioutil.WriteFile(basePath, []byte(`development:
+  store: shop.myshopify.com
+  password: abc123
+  theme_id: 123
+  readonly: true
+  ignore_files:
+  - "*.scss"
+production:
+  store: shop.myshopify.com
+  password: abc123
+  theme_id: 456
+`), 0644))
+	assert.Nil(t, ioutil.WriteFile(localPath, []byte(`development:
+  theme_id: 789
+  directory: `+dir+`
+  readonly: false
+staging:
+  store: shop.myshopify.com
+  password: abc123
+  theme_id: 111
+`), 0644))
+	assert.Nil(t, ioutil.WriteFile(otherStorePath, []byte(`development:
+  store: other.myshopify.com
+`), 0644))
+
+	conf, err := LoadAll(basePath, localPath)
+	if !assert.Nil(t, err) {
+		return
+	}
+	dev, err := conf.Get("development")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "789", dev.ThemeID)
+		assert.Equal(t, dir, dev.Directory)
+		assert.Equal(t, "abc123", dev.Password)
+		assert.Equal(t, []string{"*.scss"}, dev.IgnoredFiles)
+		assert.False(t, dev.ReadOnly)
+	}
+	prod, err := conf.Get("production")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "456", prod.ThemeID)
+	}
+	_, err = conf.Get("staging")
+	assert.Nil(t, err)
+	assert.Equal(t, ErrMergedConfig, conf.Save())
+
+	_, err = LoadAll(basePath, otherStorePath)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "changes the store from shop.myshopify.com to other.myshopify.com without setting a password")
+	}
+
+	_, err = LoadAll(basePath, filepath.Join(dir, "nope.yml"))
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "could not find config file at")
+	}
+
+	conf, err = LoadAll(filepath.Join(dir, "nope.yml"), localPath)
+	assert.True(t, os.IsNotExist(err))
+	if assert.NotNil(t, conf.Envs["staging"]) {
+		assert.Equal(t, "111", conf.Envs["staging"].ThemeID)
+	}
+}
+
+func TestSetVariables(t *testing.T) {
+	defer SetVariables(nil)
+	assert.Nil(t, SetVariables([]string{"dir=themes/dawn", " theme_id =123", "empty="}))
+	assert.Equal(t, map[string]string{"dir": "themes/dawn", "theme_id": "123", "empty": ""}, configVariables)
+
+	err := SetVariables([]string{"dir=themes/dawn", "nope"})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "invalid variable nope")
+	}
+	err = SetVariables([]string{"=value"})
+	assert.NotNil(t, err)
 }
 
 func TestSearchConfigPath(t *testing.T) {
@@ -78,9 +169,11 @@ func TestConf_Set(t *testing.T) {
 	}{
 		{name: "", initial: Env{}, err: ErrInvalidEnvironmentName.Error()},
 		{name: "development", initial: Env{}, err: "invalid environment"},
-		{name: "development", initial: Env{ThemeID: "123", Domain: "yes.myshopify.com", Password: "abc123"}, expected: Env{ThemeID: "123", Name: "development", Domain: "yes.myshopify.com", Password: "abc123", Directory: Default.Directory, Timeout: Default.Timeout}},
-		{name: "development", initial: Env{ThemeID: "123", Domain: "yes.myshopify.com", Password: "abc123", Directory: filepath.Join("..", "file")}, expected: Env{ThemeID: "123", Name: "development", Domain: "yes.myshopify.com", Password: "abc123", Directory: dir, Timeout: Default.Timeout}},
-		{name: "development", initial: Env{Domain: "yes.myshopify.com", Password: "abc123"}, overrides: []Env{{ThemeID: "12345"}}, expected: Env{Name: "development", Domain: "yes.myshopify.com", Password: "abc123", ThemeID: "12345", Directory: Default.Directory, Timeout: Default.Timeout}},
+		{name: "development", initial: Env{ThemeID: "123", Domain: "yes.myshopify.com", Password: "abc123"}, expected: Env{ThemeID: "123", ThemeIDs: []string{"123"}, Name: "development", Domain: "yes.myshopify.com", Password: "abc123", Directory: Default.Directory, Timeout: Default.Timeout}},
+		{name: "development", initial: Env{ThemeID: "123", Domain: "yes.myshopify.com", Password: "abc123", Directory: filepath.Join("..", "file")}, expected: Env{ThemeID: "123", ThemeIDs: []string{"123"}, Name: "development", Domain: "yes.myshopify.com", Password: "abc123", Directory: dir, Timeout: Default.Timeout}},
+		{name: "development", initial: Env{Domain: "yes.myshopify.com", Password: "abc123"}, overrides: []Env{{ThemeID: "12345"}}, expected: Env{Name: "development", Domain: "yes.myshopify.com", Password: "abc123", ThemeID: "12345", ThemeIDs: []string{"12345"}, Directory: Default.Directory, Timeout: Default.Timeout}},
+		{name: "development", initial: Env{ThemeID: "123", ThemeIDs: []string{"123", "456"}, Domain: "yes.myshopify.com", Password: "abc123"}, overrides: []Env{{ThemeID: "999"}}, expected: Env{Name: "development", Domain: "yes.myshopify.com", Password: "abc123", ThemeID: "999", ThemeIDs: []string{"999"}, Directory: Default.Directory, Timeout: Default.Timeout}},
+		{name: "development", initial: Env{ThemeIDs: []string{"456", "123", "456"}, Domain: "yes.myshopify.com", Password: "abc123"}, expected: Env{Name: "development", Domain: "yes.myshopify.com", Password: "abc123", ThemeID: "456", ThemeIDs: []string{"456", "123"}, Directory: Default.Directory, Timeout: Default.Timeout}},
 	}
 
 	for _, testcase := range testcases {
@@ -124,6 +217,123 @@ func TestConf_Get(t *testing.T) {
 	}
 }
 
+func TestConf_GetExtends(t *testing.T) {
+	conf := New("")
+	conf.Envs = map[string]*Env{
+		"base":        {Domain: "shop.myshopify.com", Password: "abc123", IgnoredFiles: []string{"*.png"}},
+		"staging":     {Extends: "base", ThemeID: "123"},
+		"production":  {Extends: "staging", ThemeID: "456", Password: "prod123"},
+		"loop":        {Extends: "circle", ThemeID: "123"},
+		"circle":      {Extends: "loop"},
+		"missingbase": {Extends: "nope", ThemeID: "123"},
+	}
+
+	staging, err := conf.Get("staging")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "shop.myshopify.com", staging.Domain)
+		assert.Equal(t, "abc123", staging.Password)
+		assert.Equal(t, "123", staging.ThemeID)
+		assert.Equal(t, []string{"*.png"}, staging.IgnoredFiles)
+	}
+
+	production, err := conf.Get("production", Env{ThemeID: "789"})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "shop.myshopify.com", production.Domain)
+		assert.Equal(t, "prod123", production.Password)
+		assert.Equal(t, "789", production.ThemeID)
+	}
+	assert.Equal(t, "", conf.Envs["production"].Domain)
+
+	_, err = conf.Get("loop")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "invalid environment [loop]: circular extends loop -> circle -> loop", err.Error())
+	}
+
+	_, err = conf.Get("missingbase")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "invalid environment [missingbase]: extends nope which is not defined", err.Error())
+	}
+}
+
+func TestConf_ExplicitZeroValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "themekit-config")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	configPath := filepath.Join(dir, "config.yml")
+	assert.Nil(t, ioutil.WriteFile(configPath, []byte(`base:
+  store: shop.myshopify.com
+  password: abc123
+  theme_id: 123
+  timeout: 60s
+  readonly: true
+  max_retries: 5
+  api_call_limit: 4
+zero:
+  extends: base
+  timeout: 0
+  readonly: false
+  max_retries: 0
+  api_call_limit: 0
+unset:
+  extends: base
+`), 0644))
+
+	conf, err := Load(configPath)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	zero, err := conf.Get("zero")
+	if assert.Nil(t, err) {
+		assert.Equal(t, time.Duration(0), zero.Timeout)
+		assert.False(t, zero.ReadOnly)
+		assert.Equal(t, 0, zero.MaxRetries)
+		assert.Equal(t, 0, zero.APICallLimit)
+		assert.True(t, zero.IsSet("timeout"))
+		assert.False(t, zero.IsSet("proxy"))
+	}
+
+	unset, err := conf.Get("unset")
+	if assert.Nil(t, err) {
+		assert.Equal(t, time.Minute, unset.Timeout)
+		assert.True(t, unset.ReadOnly)
+		assert.Equal(t, 5, unset.MaxRetries)
+		assert.Equal(t, 4, unset.APICallLimit)
+	}
+
+	zero, err = conf.Get("zero", Env{Timeout: 5 * time.Second})
+	if assert.Nil(t, err) {
+		assert.Equal(t, 5*time.Second, zero.Timeout)
+	}
+}
+
+func TestConf_NamedEnvironmentVariables(t *testing.T) {
+	os.Setenv("THEMEKIT_PASSWORD", "global")
+	os.Setenv("THEMEKIT_MY_SHOP_PASSWORD", "scoped")
+	os.Setenv("THEMEKIT_MY_SHOP_TIMEOUT", "42s")
+	os.Setenv("THEMEKIT_MY_SHOP_IGNORE_FILES", "a.txt:b.txt")
+	defer os.Unsetenv("THEMEKIT_PASSWORD")
+	defer os.Unsetenv("THEMEKIT_MY_SHOP_PASSWORD")
+	defer os.Unsetenv("THEMEKIT_MY_SHOP_TIMEOUT")
+	defer os.Unsetenv("THEMEKIT_MY_SHOP_IGNORE_FILES")
+
+	conf := New("")
+	scoped, err := conf.Set("my-shop", Env{Domain: "shop.myshopify.com", ThemeID: "123"})
+	assert.Nil(t, err)
+	assert.Equal(t, "scoped", scoped.Password)
+	assert.Equal(t, 42*time.Second, scoped.Timeout)
+	assert.Equal(t, []string{"a.txt", "b.txt"}, scoped.IgnoredFiles)
+
+	other, err := conf.Set("staging", Env{Domain: "shop.myshopify.com", ThemeID: "123"})
+	assert.Nil(t, err)
+	assert.Equal(t, "global", other.Password)
+}
+
+func TestEnvVarName(t *testing.T) {
+	assert.Equal(t, "PRODUCTION", envVarName("production"))
+	assert.Equal(t, "MY_SHOP_2", envVarName("my-shop.2"))
+}
+
 func TestConf_Save(t *testing.T) {
 	conf := New("")
 	conf.Set("foobar", Env{
@@ -144,6 +354,25 @@ func TestConf_Save(t *testing.T) {
 	conf = New("")
 	err = conf.save(stringBuff)
 	assert.Equal(t, err, ErrNoEnvironmentsDefined)
+
+	conf = New("")
+	conf.Set("foobar", Env{ThemeID: "123", Password: "password", Domain: "nope.myshopify.com"})
+	conf.Set("multi", Env{ThemeID: "123", ThemeIDs: []string{"456"}, Password: "password", Domain: "nope.myshopify.com"})
+	stringBuff = bytes.NewBufferString("")
+	assert.Nil(t, conf.save(stringBuff))
+	expected = `foobar:
+  password: password
+  theme_id: "123"
+  store: nope.myshopify.com
+multi:
+  password: password
+  theme_id: "123"
+  theme_ids:
+  - "123"
+  - "456"
+  store: nope.myshopify.com
+`
+	assert.Equal(t, expected, stringBuff.String())
 }
 
 func TestConf_SaveKeepsDirectoryRelativeIfItIsRelative(t *testing.T) {
@@ -2,8 +2,10 @@ package env
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -13,20 +15,53 @@ import (
 
 // Env is the structure of a configuration for an environment.
 type Env struct {
-	Name         string        `yaml:"-" json:"-" env:"-"`
-	Password     string        `yaml:"password,omitempty" json:"password,omitempty" env:"THEMEKIT_PASSWORD"`
-	ThemeID      string        `yaml:"theme_id,omitempty" json:"theme_id,omitempty" env:"THEMEKIT_THEME_ID"`
-	Domain       string        `yaml:"store" json:"store" env:"THEMEKIT_STORE"`
-	Directory    string        `yaml:"directory,omitempty" json:"directory,omitempty" env:"THEMEKIT_DIRECTORY"`
-	IgnoredFiles []string      `yaml:"ignore_files,omitempty" json:"ignore_files,omitempty" env:"THEMEKIT_IGNORE_FILES" envSeparator:":"`
-	Proxy        string        `yaml:"proxy,omitempty" json:"proxy,omitempty" env:"THEMEKIT_PROXY"`
-	Ignores      []string      `yaml:"ignores,omitempty" json:"ignores,omitempty" env:"THEMEKIT_IGNORES" envSeparator:":"`
-	Timeout      time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty" env:"THEMEKIT_TIMEOUT"`
-	ReadOnly     bool          `yaml:"readonly,omitempty" json:"readonly,omitempty" env:"-"`
-	Notify       string        `yaml:"notify,omitempty" json:"notify,omitempty" env:"THEMEKIT_NOTIFY"`
+	Name           string        `yaml:"-" json:"-" env:"-"`
+	Extends        string        `yaml:"extends,omitempty" json:"extends,omitempty" env:"-"`
+	Password       string        `yaml:"password,omitempty" json:"password,omitempty" env:"THEMEKIT_PASSWORD"`
+	PasswordFile   string        `yaml:"password_file,omitempty" json:"password_file,omitempty" env:"THEMEKIT_PASSWORD_FILE"`
+	AccessToken    string        `yaml:"access_token,omitempty" json:"access_token,omitempty" env:"THEMEKIT_ACCESS_TOKEN"`
+	APIKey         string        `yaml:"api_key,omitempty" json:"api_key,omitempty" env:"THEMEKIT_API_KEY"`
+	ThemeID        string        `yaml:"theme_id,omitempty" json:"theme_id,omitempty" env:"THEMEKIT_THEME_ID"`
+	ThemeIDs       []string      `yaml:"theme_ids,omitempty" json:"theme_ids,omitempty" env:"THEMEKIT_THEME_IDS" envSeparator:":"`
+	Domain         string        `yaml:"store" json:"store" env:"THEMEKIT_STORE"`
+	Directory      string        `yaml:"directory,omitempty" json:"directory,omitempty" env:"THEMEKIT_DIRECTORY"`
+	IgnoredFiles   []string      `yaml:"ignore_files,omitempty" json:"ignore_files,omitempty" env:"THEMEKIT_IGNORE_FILES" envSeparator:":"`
+	IncludedFiles  []string      `yaml:"include_files,omitempty" json:"include_files,omitempty" env:"THEMEKIT_INCLUDE_FILES" envSeparator:":"`
+	Proxy          string        `yaml:"proxy,omitempty" json:"proxy,omitempty" env:"THEMEKIT_PROXY"`
+	Ignores        []string      `yaml:"ignores,omitempty" json:"ignores,omitempty" env:"THEMEKIT_IGNORES" envSeparator:":"`
+	Timeout        time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty" env:"THEMEKIT_TIMEOUT"`
+	ConnectTimeout time.Duration `yaml:"connect_timeout,omitempty" json:"connect_timeout,omitempty" env:"THEMEKIT_CONNECT_TIMEOUT"`
+	ReadOnly       bool          `yaml:"readonly,omitempty" json:"readonly,omitempty" env:"-"`
+	FollowSymlinks bool          `yaml:"follow_symlinks,omitempty" json:"follow_symlinks,omitempty" env:"THEMEKIT_FOLLOW_SYMLINKS"`
+	Notify         string        `yaml:"notify,omitempty" json:"notify,omitempty" env:"THEMEKIT_NOTIFY"`
+	Debounce       time.Duration `yaml:"debounce,omitempty" json:"debounce,omitempty" env:"THEMEKIT_DEBOUNCE"`
+	MaxRetries     int           `yaml:"max_retries,omitempty" json:"max_retries,omitempty" env:"THEMEKIT_MAX_RETRIES"`
+	APICallLimit   int           `yaml:"api_call_limit,omitempty" json:"api_call_limit,omitempty" env:"THEMEKIT_API_CALL_LIMIT"`
+	APIVersion     string        `yaml:"api_version,omitempty" json:"api_version,omitempty" env:"THEMEKIT_API_VERSION"`
+	MaxFileSize    int           `yaml:"max_file_size,omitempty" json:"max_file_size,omitempty" env:"THEMEKIT_MAX_FILE_SIZE"`
+	// ContentTypes maps file extensions to the content type they are uploaded with
+	ContentTypes map[string]string `yaml:"content_types,omitempty" json:"content_types,omitempty" env:"-"`
+	// Transforms maps file globs to a command that the file is piped through before
+	// upload, the longest glob that matches a file is used
+	Transforms map[string]string `yaml:"transform,omitempty" json:"transform,omitempty" env:"-"`
+
+	// the original values of secrets that were read from a file or stdin
+	passwordRef    *string
+	accessTokenRef *string
+	// patterns loaded from the project's .themekitignore
+	projectIgnores []string
+	// the config keys that were set in the config file, even to a zero value.
+	// This is a slice because mergo cannot merge unexported maps.
+	explicit []string
 }
 
-//Default is the default values for a environment
+// apiVersionFormat matches the dated versions of the Admin REST API like 2023-10
+var apiVersionFormat = regexp.MustCompile(`^\d{4}-(0[1-9]|1[0-2])$`)
+
+// LiveThemeID can be used as the theme_id to use the published theme of the store
+const LiveThemeID = "live"
+
+// Default is the default values for a environment
 var Default = Env{
 	Name: "development",
 }
@@ -40,45 +75,163 @@ func newEnv(name string, initial Env, overrides ...Env) (*Env, error) {
 	for _, override := range overrides {
 		mergo.Merge(newConfig, &override)
 	}
+	// a theme id from a flag or the environment replaces the configured theme_ids
+	// instead of being added to them
+	if newConfig.ThemeID != "" && len(newConfig.ThemeIDs) == 0 {
+		newConfig.ThemeIDs = []string{newConfig.ThemeID}
+	}
+	overridden := *newConfig
 	mergo.Merge(newConfig, &initial)
 	mergo.Merge(newConfig, &Default)
-	return newConfig, newConfig.validate()
+	newConfig.restoreExplicit(initial, overridden)
+	newConfig.explicit = initial.explicit
+	if err := newConfig.resolveSecrets(); err != nil {
+		return newConfig, err
+	} else if err := newConfig.validate(); err != nil {
+		return newConfig, err
+	}
+	return newConfig, newConfig.loadIgnoreFile()
 }
 
 func (env *Env) validate() error {
-	errors := []string{}
+	errors := ValidationErrors{Env: env.Name}
 
 	env.ThemeID = strings.ToLower(strings.TrimSpace(env.ThemeID))
 
-	if env.ThemeID == "" {
-		errors = append(errors, "missing theme_id")
-	} else if env.ThemeID == "live" {
-		errors = append(errors, "'live' is no longer supported for theme_id. Please use an ID instead")
-	} else if _, err := strconv.ParseInt(env.ThemeID, 10, 64); err != nil {
-		errors = append(errors, "invalid theme_id")
+	var themeErrors []string
+	env.ThemeIDs, themeErrors = validateThemeIDs(env.ThemeID, env.ThemeIDs)
+	errors.add("theme_id", themeErrors...)
+	if env.ThemeID == "" && len(env.ThemeIDs) > 0 {
+		env.ThemeID = env.ThemeIDs[0]
 	}
 
+	env.Domain = normalizeDomain(env.Domain)
 	if len(env.Domain) == 0 {
-		errors = append(errors, "missing store domain")
+		errors.add("store", "missing store domain")
 	} else if !strings.HasSuffix(env.Domain, "myshopify.com") && !strings.HasSuffix(env.Domain, "myshopify.io") {
-		errors = append(errors, "invalid store domain must end in '.myshopify.com'")
+		errors.add("store", "invalid store domain must end in '.myshopify.com'")
 	}
 
-	if len(env.Password) == 0 {
-		errors = append(errors, "missing password")
+	if len(env.Password) == 0 && len(env.AccessToken) == 0 {
+		errors.add("password", "missing password or access_token")
+	}
+
+	if env.Proxy != "" {
+		errors.add("proxy", validateProxy(env.Proxy)...)
+	}
+
+	if env.Timeout < 0 {
+		errors.add("timeout", "timeout cannot be negative")
+	}
+
+	if env.ConnectTimeout < 0 {
+		errors.add("connect_timeout", "connect_timeout cannot be negative")
+	}
+
+	if env.Debounce < 0 {
+		errors.add("debounce", "debounce cannot be negative")
+	}
+
+	if env.MaxRetries < 0 {
+		errors.add("max_retries", "max_retries cannot be negative")
+	}
+
+	if env.APICallLimit < 0 {
+		errors.add("api_call_limit", "api_call_limit cannot be negative")
+	}
+
+	if env.MaxFileSize < 0 {
+		errors.add("max_file_size", "max_file_size cannot be negative")
+	}
+
+	env.APIVersion = strings.ToLower(strings.TrimSpace(env.APIVersion))
+	if env.APIVersion != "" && env.APIVersion != "unstable" && !apiVersionFormat.MatchString(env.APIVersion) {
+		errors.add("api_version", fmt.Sprintf("invalid api_version %v, must be in the form YYYY-MM or unstable", env.APIVersion))
 	}
 
 	var dirErrors []string
 	env.Directory, dirErrors = validateDirectory(env.Directory)
-	errors = append(errors, dirErrors...)
+	errors.add("directory", dirErrors...)
 
-	if len(errors) > 0 {
-		return fmt.Errorf("invalid environment [%s]: (%v)", env.Name, strings.Join(errors, ","))
+	if len(errors.Errors) > 0 {
+		return errors
 	}
 
 	return nil
 }
 
+// validateProxy makes sure the proxy uses a scheme that the http transport
+// supports, socks5 proxies are dialed natively.
+func validateProxy(proxy string) (errors []string) {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Host == "" {
+		return []string{fmt.Sprintf("invalid proxy url %v", proxy)}
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		errors = append(errors, fmt.Sprintf("unsupported proxy scheme %v, must be one of http, https or socks5", proxyURL.Scheme))
+	}
+	return errors
+}
+
+// normalizeDomain will coerce urls like https://shop.myshopify.com/admin into
+// the bare host, and a lone shop handle into a myshopify.com domain.
+func normalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if i := strings.Index(domain, "://"); i >= 0 {
+		domain = domain[i+3:]
+	}
+	if i := strings.IndexAny(domain, "/?#"); i >= 0 {
+		domain = domain[:i]
+	}
+	domain = strings.TrimPrefix(strings.TrimSuffix(domain, "."), "www.")
+
+	if domain != "" && isShopHandle(domain) {
+		domain += ".myshopify.com"
+	}
+
+	return domain
+}
+
+func isShopHandle(name string) bool {
+	for _, r := range name {
+		if !((r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// validateThemeIDs merges theme_id and theme_ids into a single de-duplicated list
+// of ids, keeping theme_id first so that it remains the primary theme.
+func validateThemeIDs(themeID string, themeIDs []string) (ids []string, errors []string) {
+	seen := map[string]bool{}
+	for _, id := range append([]string{themeID}, themeIDs...) {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if id == LiveThemeID {
+			// the live theme id is resolved once the client can list the themes
+			ids = append(ids, id)
+		} else if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+			errors = append(errors, "invalid theme_id")
+		} else {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 && len(errors) == 0 {
+		errors = append(errors, "missing theme_id")
+	}
+
+	return ids, errors
+}
+
 func validateDirectory(dir string) (finalDir string, errors []string) {
 	if fi, err := os.Lstat(filepath.Clean(dir)); err != nil {
 		errors = append(errors, fmt.Sprintf("invalid project directory %v", err))
@@ -38,6 +38,53 @@ func TestEnvNew(t *testing.T) {
 	assert.Equal(t, "flag", env.Password)
 }
 
+func TestEnv_ValidationErrors(t *testing.T) {
+	err := (&Env{ThemeID: "abc", Domain: "test.nope.com", Timeout: -time.Minute}).validate()
+	if validationErrs, ok := err.(ValidationErrors); assert.True(t, ok) {
+		assert.True(t, validationErrs.Has("theme_id"))
+		assert.True(t, validationErrs.Has("store"))
+		assert.True(t, validationErrs.Has("password"))
+		assert.True(t, validationErrs.Has("timeout"))
+		assert.False(t, validationErrs.Has("proxy"))
+		assert.Equal(t, ValidationError{Field: "theme_id", Message: "invalid theme_id"}, validationErrs.Errors[0])
+	}
+	assert.Equal(t, "invalid environment []: (invalid theme_id,invalid store domain must end in '.myshopify.com',missing password or access_token,timeout cannot be negative)", err.Error())
+}
+
+func TestNormalizeDomain(t *testing.T) {
+	testcases := []struct {
+		input, expected string
+	}{
+		{input: "shop.myshopify.com", expected: "shop.myshopify.com"},
+		{input: " Shop.myshopify.com/ ", expected: "shop.myshopify.com"},
+		{input: "https://shop.myshopify.com/admin/themes", expected: "shop.myshopify.com"},
+		{input: "http://www.shop.myshopify.com?foo=bar", expected: "shop.myshopify.com"},
+		{input: "my-shop", expected: "my-shop.myshopify.com"},
+		{input: "shop.example.com", expected: "shop.example.com"},
+		{input: "", expected: ""},
+	}
+
+	for _, testcase := range testcases {
+		assert.Equal(t, testcase.expected, normalizeDomain(testcase.input), testcase.input)
+	}
+}
+
+func TestEnv_ValidateThemeIDs(t *testing.T) {
+	e := Env{Password: "file", ThemeID: "123", Domain: "test.myshopify.com"}
+	assert.Nil(t, e.validate())
+	assert.Equal(t, []string{"123"}, e.ThemeIDs)
+
+	e = Env{Password: "file", ThemeID: " 123 ", ThemeIDs: []string{"456", "123", "456"}, Domain: "test.myshopify.com"}
+	assert.Nil(t, e.validate())
+	assert.Equal(t, "123", e.ThemeID)
+	assert.Equal(t, []string{"123", "456"}, e.ThemeIDs)
+
+	e = Env{Password: "file", ThemeIDs: []string{"789", "456"}, Domain: "test.myshopify.com"}
+	assert.Nil(t, e.validate())
+	assert.Equal(t, "789", e.ThemeID)
+	assert.Equal(t, []string{"789", "456"}, e.ThemeIDs)
+}
+
 func TestEnv_Validate(t *testing.T) {
 	testCases := []struct {
 		env        Env
@@ -45,12 +92,32 @@ func TestEnv_Validate(t *testing.T) {
 		notwindows bool
 	}{
 		{env: Env{Password: "file", ThemeID: "123", Domain: "test.myshopify.com"}},
-		{env: Env{Password: "file", ThemeID: "live", Domain: "test.myshopify.com"}, err: "invalid environment []: ('live' is no longer supported for theme_id. Please use an ID instead)"},
+		{env: Env{Password: "file", ThemeID: "live", Domain: "test.myshopify.com"}},
+		{env: Env{Password: "file", ThemeID: "LIVE", Domain: "test.myshopify.com"}},
+		{env: Env{Password: "file", ThemeID: "123", Domain: "test.myshopify.com", APIVersion: "2023-10"}},
+		{env: Env{Password: "file", ThemeID: "123", Domain: "test.myshopify.com", MaxFileSize: -1}, err: "max_file_size cannot be negative"},
+		{env: Env{Password: "file", ThemeID: "123", Domain: "test.myshopify.com", APIVersion: "unstable"}},
+		{env: Env{Password: "file", ThemeID: "123", Domain: "test.myshopify.com", APIVersion: "2023-13"}, err: "invalid api_version 2023-13, must be in the form YYYY-MM or unstable"},
+		{env: Env{Password: "file", ThemeID: "123", Domain: "test.myshopify.com", APIVersion: "latest"}, err: "invalid api_version latest"},
 		{env: Env{ThemeID: "123", Domain: "test.myshopify.com"}, err: "missing password"},
+		{env: Env{AccessToken: "shpat_123", ThemeID: "123", Domain: "test.myshopify.com"}},
 		{env: Env{Password: "test", ThemeID: "123", Domain: "test.nope.com"}, err: "invalid store domain"},
 		{env: Env{Password: "test", ThemeID: "123"}, err: "missing store domain"},
+		{env: Env{Password: "test", ThemeID: "123", Domain: "test.myshopify.com", Proxy: "http://127.0.0.1:8080"}},
+		{env: Env{Password: "test", ThemeID: "123", Domain: "test.myshopify.com", Proxy: "socks5://127.0.0.1:1080"}},
+		{env: Env{Password: "test", ThemeID: "123", Domain: "test.myshopify.com", Proxy: "ftp://127.0.0.1:21"}, err: "unsupported proxy scheme ftp"},
+		{env: Env{Password: "test", ThemeID: "123", Domain: "test.myshopify.com", Proxy: "localhost"}, err: "invalid proxy url"},
+		{env: Env{Password: "test", ThemeID: "123", Domain: "test.myshopify.com", ConnectTimeout: -1}, err: "connect_timeout cannot be negative"},
+		{env: Env{Password: "test", ThemeID: "123", Domain: "test.myshopify.com", Timeout: -time.Minute}, err: "timeout cannot be negative"},
+		{env: Env{Password: "test", ThemeID: "123", Domain: "test.myshopify.com", Debounce: -time.Second}, err: "debounce cannot be negative"},
+		{env: Env{Password: "test", ThemeID: "123", Domain: "https://test.myshopify.com/admin"}},
+		{env: Env{Password: "test", ThemeID: "123", Domain: "test"}},
+		{env: Env{Password: "test", ThemeID: "123", Domain: "https://"}, err: "missing store domain"},
+		{env: Env{Password: "test", ThemeID: "123", Domain: "my shop"}, err: "invalid store domain"},
 		{env: Env{Password: "test", Domain: "test.myshopify.com"}, err: "missing theme_id"},
 		{env: Env{Password: "file", ThemeID: "abc", Domain: "test.myshopify.com"}, err: "invalid theme_id"},
+		{env: Env{Password: "file", ThemeIDs: []string{"123", "456"}, Domain: "test.myshopify.com"}},
+		{env: Env{Password: "file", ThemeID: "123", ThemeIDs: []string{"abc"}, Domain: "test.myshopify.com"}, err: "invalid theme_id"},
 		{notwindows: true, env: Env{Password: "abc123", Domain: "test.myshopify.com", ThemeID: "123", Directory: filepath.Join("_testdata", "symlink_projectdir")}},
 		{notwindows: true, env: Env{Password: "abc123", Domain: "test.myshopify.com", Directory: filepath.Join("_testdata", "bad_symlink")}, err: "invalid project symlink"},
 		{notwindows: true, env: Env{Password: "abc123", Domain: "test.myshopify.com", Directory: filepath.Join("_testdata", "symlink_file")}, err: "is not a directory"},
@@ -0,0 +1,48 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError describes a single invalid field of an environment. The field
+// is the name of the field as it is written in the config file.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (err ValidationError) Error() string {
+	return err.Message
+}
+
+// ValidationErrors is returned when an environment is invalid and holds every
+// problem that was found with it.
+type ValidationErrors struct {
+	Env    string
+	Errors []ValidationError
+}
+
+func (errs ValidationErrors) Error() string {
+	messages := []string{}
+	for _, err := range errs.Errors {
+		messages = append(messages, err.Message)
+	}
+	return fmt.Sprintf("invalid environment [%s]: (%v)", errs.Env, strings.Join(messages, ","))
+}
+
+// Has will return true if the field is one of the invalid fields
+func (errs ValidationErrors) Has(field string) bool {
+	for _, err := range errs.Errors {
+		if err.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (errs *ValidationErrors) add(field string, messages ...string) {
+	for _, message := range messages {
+		errs.Errors = append(errs.Errors, ValidationError{Field: field, Message: message})
+	}
+}
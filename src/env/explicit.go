@@ -0,0 +1,81 @@
+package env
+
+import (
+	"encoding/json"
+
+	"gopkg.in/yaml.v1"
+)
+
+// IsSet returns true if the config key was explicitly set in the config file,
+// even if it was set to a zero value like timeout: 0 or readonly: false.
+func (env *Env) IsSet(key string) bool {
+	for _, explicit := range env.explicit {
+		if explicit == key {
+			return true
+		}
+	}
+	return false
+}
+
+// markExplicit records which keys every environment sets in the config contents
+// so that explicit zero values can be told apart from values that were not set.
+func (c *Conf) markExplicit(contents []byte, ext string) error {
+	raw := map[string]map[string]interface{}{}
+	var err error
+	if ext == "json" {
+		err = json.Unmarshal(contents, &raw)
+	} else {
+		err = yaml.Unmarshal(contents, &raw)
+	}
+	if err != nil {
+		return err
+	}
+
+	for name, values := range raw {
+		if env := c.Envs[name]; env != nil {
+			env.explicit = []string{}
+			for key := range values {
+				env.explicit = append(env.explicit, key)
+			}
+		}
+	}
+	return nil
+}
+
+// restoreExplicit copies the settings where a zero value means something, like
+// timeout: 0 disabling the timeout, from src into the environment when src sets
+// them explicitly. mergo treats zero values as unset so without this they would
+// be replaced by a value with a lower precedence. Settings that are set in
+// unless are not copied because they have a higher precedence than src.
+func (env *Env) restoreExplicit(src Env, unless Env) {
+	for _, key := range src.explicit {
+		switch key {
+		case "timeout":
+			if unless.Timeout == 0 {
+				env.Timeout = src.Timeout
+			}
+		case "max_retries":
+			if unless.MaxRetries == 0 {
+				env.MaxRetries = src.MaxRetries
+			}
+		case "api_call_limit":
+			if unless.APICallLimit == 0 {
+				env.APICallLimit = src.APICallLimit
+			}
+		case "readonly":
+			if !unless.ReadOnly {
+				env.ReadOnly = src.ReadOnly
+			}
+		}
+	}
+}
+
+func mergeExplicit(env, base Env) []string {
+	merged := append([]string{}, env.explicit...)
+	for _, key := range base.explicit {
+		if !env.IsSet(key) {
+			merged = append(merged, key)
+		}
+	}
+	return merged
+}
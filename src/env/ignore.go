@@ -0,0 +1,50 @@
+package env
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFileName is the name of the ignore file that is loaded from the root of
+// the project directory.
+const ignoreFileName = ".themekitignore"
+
+// loadIgnoreFile will add the patterns from the project's .themekitignore to
+// the ignored files. The loaded patterns are remembered so that they are not
+// written out to the config file.
+func (env *Env) loadIgnoreFile() error {
+	data, err := ioutil.ReadFile(filepath.Join(env.Directory, ignoreFileName))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("invalid environment [%s]: could not read %s: %v", env.Name, ignoreFileName, err)
+	}
+
+	env.projectIgnores = []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+		if line != "" && !strings.HasPrefix(line, "#") {
+			env.projectIgnores = append(env.projectIgnores, line)
+		}
+	}
+	env.IgnoredFiles = append(append([]string{}, env.projectIgnores...), withoutPatterns(env.IgnoredFiles, env.projectIgnores)...)
+	return nil
+}
+
+// withoutPatterns returns the patterns that are not in the excluded patterns
+func withoutPatterns(patterns, excluded []string) []string {
+	remaining := []string{}
+	for _, pattern := range patterns {
+		found := false
+		for _, exclude := range excluded {
+			found = found || pattern == exclude
+		}
+		if !found {
+			remaining = append(remaining, pattern)
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,36 @@
+package env
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnv_LoadIgnoreFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "themekit-ignore")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	e := &Env{Directory: dir, IgnoredFiles: []string{"*.png"}}
+	assert.Nil(t, e.loadIgnoreFile())
+	assert.Equal(t, []string{"*.png"}, e.IgnoredFiles)
+
+	contents := "# build output\nassets/*.map\n\n  node_modules/  \r\n*.png\n"
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, ignoreFileName), []byte(contents), 0644))
+	assert.Nil(t, e.loadIgnoreFile())
+	assert.Equal(t, []string{"assets/*.map", "node_modules/", "*.png"}, e.IgnoredFiles)
+
+	e, err = newEnv("development", Env{Domain: "shop.myshopify.com", Password: "abc123", ThemeID: "123", Directory: dir, IgnoredFiles: []string{"*.scss"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"assets/*.map", "node_modules/", "*.png", "*.scss"}, e.IgnoredFiles)
+
+	conf := Conf{Envs: map[string]*Env{"development": e}, path: filepath.Join(dir, "config.yml")}
+	buffer := bytes.NewBufferString("")
+	assert.Nil(t, conf.save(buffer))
+	assert.Contains(t, buffer.String(), "*.scss")
+	assert.NotContains(t, buffer.String(), "node_modules")
+}
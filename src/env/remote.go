@@ -0,0 +1,69 @@
+package env
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const configTokenVar = "THEMEKIT_CONFIG_TOKEN"
+
+var remoteConfigTimeout = 10 * time.Second
+
+func isRemoteConfig(configPath string) bool {
+	return strings.HasPrefix(configPath, "http://") || strings.HasPrefix(configPath, "https://")
+}
+
+// fetchRemoteConfig will request the config from the url provided. If the
+// THEMEKIT_CONFIG_TOKEN variable is set it will be sent as a bearer token.
+func fetchRemoteConfig(configURL, proxy string) ([]byte, string, error) {
+	location, err := url.Parse(configURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid config url %v: %v", configURL, err)
+	}
+
+	ext := strings.TrimPrefix(path.Ext(location.Path), ".")
+	if ext != "json" {
+		ext = "yml"
+	}
+
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid proxy url %v: %v", proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	req, err := http.NewRequest("GET", configURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not fetch config from %v: %v", configURL, err)
+	}
+	if token := os.Getenv(configTokenVar); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{Transport: transport, Timeout: remoteConfigTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not fetch config from %v: %v", configURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("could not fetch config from %v: server responded with %v", configURL, resp.Status)
+	}
+
+	contents, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not read config from %v: %v", configURL, err)
+	}
+
+	return contents, ext, nil
+}
@@ -0,0 +1,66 @@
+package env
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad_RemoteConfig(t *testing.T) {
+	var authHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/config.yml":
+			w.Write([]byte("development:\n  password: abc123\n  store: example.myshopify.com\n  theme_id: \"123\"\n"))
+		case "/config.json":
+			w.Write([]byte(`{"development": {"password": "abc123", "store": "example.myshopify.com", "theme_id": "123"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	os.Setenv(configTokenVar, "secret")
+	defer os.Unsetenv(configTokenVar)
+
+	for _, path := range []string{"/config.yml", "/config.json"} {
+		conf, err := Load(server.URL + path)
+		assert.Nil(t, err, path)
+		assert.Equal(t, "Bearer secret", authHeader)
+		if assert.NotNil(t, conf.Envs["development"], path) {
+			assert.Equal(t, "example.myshopify.com", conf.Envs["development"].Domain)
+			assert.Equal(t, "123", conf.Envs["development"].ThemeID)
+		}
+	}
+
+	_, err := Load(server.URL + "/missing.yml")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "could not fetch config from")
+		assert.Contains(t, err.Error(), "404")
+	}
+
+	_, err = Load("http://127.0.0.1:0/config.yml")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "could not fetch config from")
+	}
+}
+
+func TestLoad_RemoteConfigProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte("development:\n  password: abc123\n  store: example.myshopify.com\n  theme_id: \"123\"\n"))
+	}))
+	defer proxy.Close()
+
+	SetProxy(proxy.URL)
+	defer SetProxy("")
+	conf, err := Load("http://config.example.invalid/config.yml")
+	assert.Nil(t, err)
+	assert.Equal(t, "http://config.example.invalid/config.yml", proxied)
+	assert.Equal(t, ErrRemoteConfig, conf.Save())
+}
@@ -0,0 +1,95 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+)
+
+// redactedSecret replaces secrets in any output
+const redactedSecret = "********"
+
+var (
+	stdin       io.Reader = os.Stdin
+	stdinOnce   sync.Once
+	stdinSecret string
+	stdinErr    error
+)
+
+// resolveSecrets will replace secret references with the secret that they point
+// to. The original reference is kept so that saving the config does not write
+// out the secret.
+func (env *Env) resolveSecrets() error {
+	if env.Password == "" && env.PasswordFile != "" {
+		secret, err := readSecretFile(env.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("invalid environment [%s]: could not read password_file: %v", env.Name, err)
+		}
+		env.Password, env.passwordRef = secret, new(string)
+	} else if secret, isRef, err := resolveSecret(env.Password); err != nil {
+		return fmt.Errorf("invalid environment [%s]: could not read password: %v", env.Name, err)
+	} else if isRef {
+		ref := env.Password
+		env.Password, env.passwordRef = secret, &ref
+	}
+
+	if secret, isRef, err := resolveSecret(env.AccessToken); err != nil {
+		return fmt.Errorf("invalid environment [%s]: could not read access_token: %v", env.Name, err)
+	} else if isRef {
+		ref := env.AccessToken
+		env.AccessToken, env.accessTokenRef = secret, &ref
+	}
+
+	return nil
+}
+
+// resolveSecret will read the secret from stdin if the value is -, or from a file
+// if the value is prefixed with file:// or @. Otherwise the value is returned as is.
+func resolveSecret(value string) (secret string, isRef bool, err error) {
+	switch {
+	case value == "-":
+		secret, err = readStdinSecret()
+		return secret, true, err
+	case strings.HasPrefix(value, "file://"):
+		secret, err = readSecretFile(strings.TrimPrefix(value, "file://"))
+		return secret, true, err
+	case strings.HasPrefix(value, "@"):
+		secret, err = readSecretFile(strings.TrimPrefix(value, "@"))
+		return secret, true, err
+	}
+	return value, false, nil
+}
+
+func readSecretFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// readStdinSecret only reads stdin once so that every environment referencing it
+// gets the same secret.
+func readStdinSecret() (string, error) {
+	stdinOnce.Do(func() {
+		var data []byte
+		data, stdinErr = ioutil.ReadAll(stdin)
+		stdinSecret = strings.TrimRight(string(data), "\r\n")
+	})
+	return stdinSecret, stdinErr
+}
+
+// Redacted returns a copy of the environment with its secrets masked so that it
+// can be printed safely.
+func (env Env) Redacted() Env {
+	if env.Password != "" {
+		env.Password = redactedSecret
+	}
+	if env.AccessToken != "" {
+		env.AccessToken = redactedSecret
+	}
+	return env
+}
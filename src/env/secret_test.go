@@ -0,0 +1,88 @@
+package env
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "themekit-secret")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	secretPath := filepath.Join(dir, "password")
+	assert.Nil(t, ioutil.WriteFile(secretPath, []byte("shppa_secret\n"), 0600))
+
+	defer func(original io.Reader) { stdin = original }(stdin)
+	stdin = strings.NewReader("shpat_stdin\r\n")
+	stdinOnce = sync.Once{}
+
+	testcases := []struct {
+		value, secret, err string
+		isRef              bool
+	}{
+		{value: "plain", secret: "plain"},
+		{value: "", secret: ""},
+		{value: "file://" + secretPath, secret: "shppa_secret", isRef: true},
+		{value: "@" + secretPath, secret: "shppa_secret", isRef: true},
+		{value: "-", secret: "shpat_stdin", isRef: true},
+		{value: "-", secret: "shpat_stdin", isRef: true},
+		{value: "@" + filepath.Join(dir, "nope"), isRef: true, err: "no such file"},
+	}
+
+	for _, testcase := range testcases {
+		secret, isRef, err := resolveSecret(testcase.value)
+		assert.Equal(t, testcase.isRef, isRef, testcase.value)
+		if testcase.err == "" {
+			assert.Nil(t, err)
+			assert.Equal(t, testcase.secret, secret, testcase.value)
+		} else if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), testcase.err)
+		}
+	}
+}
+
+func TestConf_SecretsAreNotSaved(t *testing.T) {
+	dir, err := ioutil.TempDir("", "themekit-secret")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	secretPath := filepath.Join(dir, "password")
+	assert.Nil(t, ioutil.WriteFile(secretPath, []byte("shppa_secret\n"), 0600))
+
+	conf := New(filepath.Join(dir, "config.yml"))
+	e, err := conf.Set("development", Env{Domain: "shop.myshopify.com", ThemeID: "123", Directory: dir, PasswordFile: secretPath})
+	assert.Nil(t, err)
+	assert.Equal(t, "shppa_secret", e.Password)
+
+	e, err = conf.Set("production", Env{Domain: "shop.myshopify.com", ThemeID: "123", Directory: dir, AccessToken: "file://" + secretPath})
+	assert.Nil(t, err)
+	assert.Equal(t, "shppa_secret", e.AccessToken)
+
+	buf := bytes.NewBufferString("")
+	assert.Nil(t, conf.save(buf))
+	assert.NotContains(t, buf.String(), "shppa_secret")
+	assert.Contains(t, buf.String(), "password_file: "+secretPath)
+	assert.Contains(t, buf.String(), "access_token: file://"+secretPath)
+
+	_, err = conf.Set("broken", Env{Domain: "shop.myshopify.com", ThemeID: "123", Directory: dir, PasswordFile: filepath.Join(dir, "nope")})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "could not read password_file")
+	}
+}
+
+func TestEnv_Redacted(t *testing.T) {
+	e := Env{Password: "secret", AccessToken: "shpat_secret", Domain: "shop.myshopify.com"}
+	redacted := e.Redacted()
+	assert.Equal(t, redactedSecret, redacted.Password)
+	assert.Equal(t, redactedSecret, redacted.AccessToken)
+	assert.Equal(t, "shop.myshopify.com", redacted.Domain)
+	assert.Equal(t, "secret", e.Password)
+	assert.Equal(t, "", Env{}.Redacted().Password)
+}
@@ -3,6 +3,7 @@ package file
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -29,13 +30,26 @@ var defaultGlobs = []string{}
 
 // Filter matches filepaths to a list of patterns
 type Filter struct {
-	rootDir string
+	rootDir        string
+	regexps        []*regexp.Regexp
+	globs          []string
+	includeRegexps []*regexp.Regexp
+	includeGlobs   []string
+	rules          []rule
+}
+
+// rule is a single user ignore pattern. A negated rule re-includes paths that
+// were ignored by an earlier rule.
+type rule struct {
+	negate  bool
 	regexps []*regexp.Regexp
 	globs   []string
 }
 
-// NewFilter will create a new file path filter
-func NewFilter(rootDir string, patterns []string, files []string) (Filter, error) {
+// NewFilter will create a new file path filter. If any include patterns are
+// provided then only paths matching one of those patterns will pass the filter,
+// the ignore patterns are still applied on top of the included paths.
+func NewFilter(rootDir string, patterns []string, files []string, includes []string) (Filter, error) {
 	filePatterns, err := filesToPatterns(files)
 	if err != nil {
 		return Filter{}, err
@@ -45,12 +59,15 @@ func NewFilter(rootDir string, patterns []string, files []string) (Filter, error
 		rootDir += "/"
 	}
 
-	regexps, globs := patternsToRegexpsAndGlobs(append(patterns, filePatterns...))
+	includeRegexps, includeGlobs := compilePatterns(nil, nil, includes)
 
 	return Filter{
-		rootDir: rootDir,
-		regexps: regexps,
-		globs:   globs,
+		rootDir:        rootDir,
+		regexps:        defaultRegexes,
+		globs:          defaultGlobs,
+		includeRegexps: includeRegexps,
+		includeGlobs:   includeGlobs,
+		rules:          compileRules(append(patterns, filePatterns...)),
 	}, nil
 }
 
@@ -60,14 +77,46 @@ func (f Filter) Match(path string) bool {
 		return true
 	}
 
-	for _, regexp := range f.regexps {
+	relPath := "/" + relativePath(f.rootDir, path)
+	if !isProjectDirectory(f.rootDir, path) && !f.included(path, relPath) {
+		return true
+	}
+
+	// the default ignores can never be negated
+	if matchAny(f.regexps, f.globs, path, relPath) {
+		return true
+	}
+
+	// rules are evaluated in order so the last one to match decides
+	ignored := false
+	for _, rule := range f.rules {
+		if rule.negate == ignored && matchAny(rule.regexps, rule.globs, path, relPath) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// included will return true if there are no include patterns or if the path
+// matches at least one of them.
+func (f Filter) included(path, relPath string) bool {
+	if len(f.includeRegexps) == 0 && len(f.includeGlobs) == 0 {
+		return true
+	}
+	return matchAny(f.includeRegexps, f.includeGlobs, path, relPath)
+}
+
+// matchAny will check the regexps against the path as it was given and the globs
+// against the path relative to the project root so that anchored patterns work.
+func matchAny(regexps []*regexp.Regexp, globs []string, path, relPath string) bool {
+	for _, regexp := range regexps {
 		if regexp.MatchString(path) {
 			return true
 		}
 	}
 
-	for _, pattern := range f.globs {
-		if glob.Glob(pattern, path) {
+	for _, pattern := range globs {
+		if glob.Glob(pattern, relPath) {
 			return true
 		}
 	}
@@ -99,14 +148,30 @@ func filesToPatterns(files []string) ([]string, error) {
 	return patterns, nil
 }
 
-// patternsToFiltersAndGlobs will take in string patterns and convert them to either
-// regex patters or glob patterns so that they are handled in an expected manner.
-func patternsToRegexpsAndGlobs(patterns []string) ([]*regexp.Regexp, []string) {
-	regexps := defaultRegexes
-	globs := defaultGlobs
+// compileRules will convert the patterns into rules in the order they were
+// given, patterns prefixed with ! become negated rules.
+func compileRules(patterns []string) []rule {
+	var rules []rule
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		negate := strings.HasPrefix(pattern, "!")
+		regexps, globs := compilePatterns(nil, nil, []string{strings.TrimPrefix(pattern, "!")})
+		if len(regexps) == 0 && len(globs) == 0 {
+			continue
+		}
+		rules = append(rules, rule{negate: negate, regexps: regexps, globs: globs})
+	}
+	return rules
+}
 
+// compilePatterns will append the converted patterns onto the regexps and globs
+// passed in.
+func compilePatterns(regexps []*regexp.Regexp, globs []string, patterns []string) ([]*regexp.Regexp, []string) {
 	for _, pattern := range patterns {
 		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
 
 		//full regex
 		if strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
@@ -119,14 +184,38 @@ func patternsToRegexpsAndGlobs(patterns []string) ([]*regexp.Regexp, []string) {
 			pattern += "*"
 		}
 
-		// The pattern will be scoped to root directory so it should match anything
-		// within that space
-		if !strings.HasPrefix(pattern, "*") {
-			pattern = "*" + pattern
+		for _, expanded := range expandDoubleStar(pattern) {
+			// A leading slash anchors the pattern to the root directory, otherwise
+			// it is scoped to the root directory so it should match anything within
+			// that space
+			if !strings.HasPrefix(expanded, "/") && !strings.HasPrefix(expanded, "*") {
+				expanded = "*" + expanded
+			}
+			globs = append(globs, expanded)
 		}
-
-		globs = append(globs, pattern)
 	}
 
 	return regexps, globs
 }
+
+// expandDoubleStar will convert ** into globs. Since * already matches across
+// directories, a/**/b only needs an extra pattern to match a/b.
+func expandDoubleStar(pattern string) []string {
+	if !strings.Contains(pattern, "**") {
+		return []string{pattern}
+	}
+
+	patterns := []string{strings.Replace(pattern, "**", "*", -1)}
+	if strings.Contains(pattern, "/**/") {
+		patterns = append(patterns, strings.Replace(strings.Replace(pattern, "/**/", "/", -1), "**", "*", -1))
+	}
+	return patterns
+}
+
+// relativePath will return the path relative to the root directory
+func relativePath(root, filename string) string {
+	return strings.TrimPrefix(
+		filepath.ToSlash(filepath.Clean(filename)),
+		filepath.ToSlash(filepath.Clean(root)+"/"),
+	)
+}
@@ -13,11 +13,11 @@ func TestNewFilter(t *testing.T) {
 		regexps: defaultRegexes,
 		globs:   defaultGlobs,
 	}
-	actual, err := NewFilter("/tmp", []string{}, []string{})
+	actual, err := NewFilter("/tmp", []string{}, []string{}, []string{})
 	assert.Nil(t, err)
 	assert.Equal(t, expected, actual)
 
-	_, err = NewFilter("/tmp", []string{}, []string{"does not exists"})
+	_, err = NewFilter("/tmp", []string{}, []string{"does not exists"}, []string{})
 	assert.NotNil(t, err)
 }
 
@@ -57,6 +57,67 @@ func TestFilter_Match(t *testing.T) {
 	}
 }
 
+func TestFilter_MatchIncludes(t *testing.T) {
+	filter, err := NewFilter("/tmp", []string{"*.bak"}, []string{}, []string{"templates/", "sections/*.liquid"})
+	assert.Nil(t, err)
+
+	testcases := []struct {
+		input   string
+		matches bool
+	}{
+		{input: "templates/index.liquid", matches: false},
+		{input: "/tmp/templates/customers/login.liquid", matches: false},
+		{input: "sections/header.liquid", matches: false},
+		{input: "templates/index.liquid.bak", matches: true},
+		{input: "sections/header.json", matches: true},
+		{input: "assets/app.js", matches: true},
+		{input: "/tmp/assets", matches: false},
+	}
+
+	for _, testcase := range testcases {
+		assert.Equal(t, testcase.matches, filter.Match(testcase.input), testcase.input)
+	}
+}
+
+func TestFilter_MatchGitignoreSemantics(t *testing.T) {
+	testcases := []struct {
+		patterns []string
+		input    string
+		matches  bool
+	}{
+		{patterns: []string{"assets/**/*.map"}, input: "/tmp/assets/vendor/app.js.map", matches: true},
+		{patterns: []string{"assets/**/*.map"}, input: "/tmp/assets/app.js.map", matches: true},
+		{patterns: []string{"assets/**/*.map"}, input: "/tmp/assets/app.js", matches: false},
+		{patterns: []string{"**/draft.liquid"}, input: "/tmp/templates/draft.liquid", matches: true},
+		{patterns: []string{"**/draft.liquid"}, input: "/tmp/templates/nodraft.liquid", matches: false},
+		{patterns: []string{"/templates/index.liquid"}, input: "/tmp/templates/index.liquid", matches: true},
+		{patterns: []string{"/templates/index.liquid"}, input: "templates/index.liquid", matches: true},
+		{patterns: []string{"/index.liquid"}, input: "/tmp/templates/index.liquid", matches: false},
+		{patterns: []string{"templates/customers/"}, input: "/tmp/templates/customers/login.liquid", matches: true},
+		{patterns: []string{"templates/customers/"}, input: "/tmp/templates/index.liquid", matches: false},
+		{patterns: []string{"*.json", "!config/settings_schema.json"}, input: "/tmp/config/settings_data.json", matches: true},
+		{patterns: []string{"*.json", "!config/settings_schema.json"}, input: "/tmp/config/settings_schema.json", matches: false},
+		{patterns: []string{"!templates/index.liquid"}, input: "/tmp/templates/index.liquid", matches: false},
+		{patterns: []string{"!config/settings_schema.json", "*.json"}, input: "/tmp/config/settings_schema.json", matches: true},
+		{patterns: []string{"*.json", "!config/*.json", "config/settings_data.json"}, input: "/tmp/config/settings_data.json", matches: true},
+		{patterns: []string{"*.json", "!config/*.json", "config/settings_data.json"}, input: "/tmp/config/settings_schema.json", matches: false},
+		{patterns: []string{"!node_modules/"}, input: "/tmp/node_modules/lib/index.js", matches: true},
+		{patterns: []string{"!.git/"}, input: "/tmp/.git/HEAD", matches: true},
+	}
+
+	for _, testcase := range testcases {
+		filter, err := NewFilter("/tmp", testcase.patterns, []string{}, []string{})
+		assert.Nil(t, err)
+		assert.Equal(t, testcase.matches, filter.Match(testcase.input), "%v %v", testcase.patterns, testcase.input)
+	}
+}
+
+func TestExpandDoubleStar(t *testing.T) {
+	assert.Equal(t, []string{"templates/*.liquid"}, expandDoubleStar("templates/*.liquid"))
+	assert.Equal(t, []string{"*/foo"}, expandDoubleStar("**/foo"))
+	assert.Equal(t, []string{"a/*/b", "a/b"}, expandDoubleStar("a/**/b"))
+}
+
 func TestFilesToPatterns(t *testing.T) {
 	patterns, err := filesToPatterns([]string{"_testdata/ignores_file"})
 	assert.Nil(t, err)
@@ -66,25 +127,32 @@ func TestFilesToPatterns(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
-func TestPatternsToRegexpsAndGlobs(t *testing.T) {
+func TestCompileRules(t *testing.T) {
 	testcases := []struct {
 		pattern string
+		negate  bool
 		glob    string
 		regex   *regexp.Regexp
 	}{
 		{pattern: "config/settings.json", glob: "*config/settings.json"},
 		{pattern: "config/", glob: "*config/*"},
 		{pattern: "*.png", glob: "*.png"},
+		{pattern: "!*.png", negate: true, glob: "*.png"},
 		{pattern: `/\.(txt|gif|bat)$/`, regex: regexp.MustCompile(`\.(txt|gif|bat)$`)},
 	}
 
 	for _, testcase := range testcases {
-		regexps, globs := patternsToRegexpsAndGlobs([]string{testcase.pattern})
-		if testcase.regex != nil {
-			assert.Equal(t, append(defaultRegexes, testcase.regex), regexps)
-		}
-		if testcase.glob != "" {
-			assert.Equal(t, append(defaultGlobs, testcase.glob), globs)
+		rules := compileRules([]string{testcase.pattern})
+		if assert.Len(t, rules, 1) {
+			assert.Equal(t, testcase.negate, rules[0].negate)
+			if testcase.regex != nil {
+				assert.Equal(t, []*regexp.Regexp{testcase.regex}, rules[0].regexps)
+			}
+			if testcase.glob != "" {
+				assert.Equal(t, []string{testcase.glob}, rules[0].globs)
+			}
 		}
 	}
+
+	assert.Nil(t, compileRules([]string{"", "  "}))
 }
@@ -0,0 +1,30 @@
+package file
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchPath(t *testing.T) {
+	testcases := []struct {
+		pattern, name string
+		matches       bool
+	}{
+		{pattern: "assets/logo.png", name: "assets/logo.png", matches: true},
+		{pattern: "assets/*", name: "assets/logo.png", matches: true},
+		{pattern: "sections/*", name: "sections/group/header.liquid", matches: false},
+		{pattern: "sections/**", name: "sections/group/header.liquid", matches: true},
+		{pattern: "sections/**", name: "sections/header.liquid", matches: true},
+		{pattern: "sections/**/*.liquid", name: "sections/a/b/header.liquid", matches: true},
+		{pattern: "sections/**/*.liquid", name: "sections/header.liquid", matches: true},
+		{pattern: "sections/**/*.liquid", name: "sections/a/header.json", matches: false},
+		{pattern: "**/*.json", name: "config/settings_data.json", matches: true},
+		{pattern: "**/*.json", name: "templates/customers/login.liquid", matches: false},
+		{pattern: "templates/**/login.liquid", name: "snippets/login.liquid", matches: false},
+	}
+
+	for _, testcase := range testcases {
+		assert.Equal(t, testcase.matches, MatchPath(testcase.pattern, testcase.name), testcase.pattern+" "+testcase.name)
+	}
+}
@@ -1,8 +1,10 @@
 package file
 
 import (
+	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
 )
 
 var (
@@ -56,3 +58,32 @@ func pathToProject(root, filename string) string {
 
 	return ""
 }
+
+// IsCaseInsensitive reports if the filesystem that dir is on ignores the case of
+// file names, it checks if the same directory can be found with its case swapped.
+func IsCaseInsensitive(dir string) bool {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	swapped := swapCase(abs)
+	if swapped == abs {
+		return false
+	}
+
+	info, err := os.Stat(abs)
+	if err != nil {
+		return false
+	}
+	swappedInfo, err := os.Stat(swapped)
+	return err == nil && os.SameFile(info, swappedInfo)
+}
+
+func swapCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsUpper(r) {
+			return unicode.ToLower(r)
+		}
+		return unicode.ToUpper(r)
+	}, s)
+}
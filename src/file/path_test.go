@@ -73,3 +73,8 @@ func TestPathInProject(t *testing.T) {
 		assert.Equal(t, expected, pathInProject(root, input), input)
 	}
 }
+
+func TestIsCaseInsensitive(t *testing.T) {
+	assert.False(t, IsCaseInsensitive(filepath.Join("_testdata", "nope")))
+	assert.Equal(t, "ABc/D", swapCase("abC/d"))
+}
@@ -26,11 +26,28 @@ const (
 	Get
 )
 
+// String returns a short name for the op that is suitable for output
+func (op Op) String() string {
+	switch op {
+	case Update:
+		return "update"
+	case Remove:
+		return "remove"
+	case Skip:
+		return "skip"
+	case Get:
+		return "download"
+	default:
+		return "unknown"
+	}
+}
+
 var (
-	// how long until we stop trying to drain events before emitting events
-	drainTimeout = time.Second
-	// the interval that the watcher polls the filesystem this needs to be less than
-	// the drain timeout, otherwise debouncing will not work
+	// how long until we stop trying to drain events before emitting events, when
+	// the environment does not set a debounce
+	defaultDebounce = 200 * time.Millisecond
+	// the longest interval that the watcher polls the filesystem. The interval is
+	// shortened to less than the debounce, otherwise debouncing will not work
 	pollInterval = 500 * time.Millisecond
 )
 
@@ -50,6 +67,7 @@ type Watcher struct {
 	fsWatcher *watcher.Watcher
 	directory string
 	checksums map[string]string
+	debounce  time.Duration
 }
 
 // NewWatcher will create a new file change watching for a given directory defined
@@ -75,16 +93,22 @@ func NewWatcher(e *env.Env, configPath string, checksums map[string]string) (*Wa
 		}
 	}
 
+	debounce := e.Debounce
+	if debounce <= 0 {
+		debounce = defaultDebounce
+	}
+
 	return &Watcher{
 		Events:    make(chan Event),
 		directory: e.Directory,
 		checksums: checksums,
 		fsWatcher: fsWatcher,
+		debounce:  debounce,
 	}, nil
 }
 
 func filterHook(e *env.Env, configPath string) (watcher.FilterFileHookFunc, error) {
-	filter, err := NewFilter(e.Directory, e.IgnoredFiles, e.Ignores)
+	filter, err := NewFilter(e.Directory, e.IgnoredFiles, e.Ignores, e.IncludedFiles)
 	if err != nil {
 		return nil, err
 	}
@@ -100,7 +124,16 @@ func filterHook(e *env.Env, configPath string) (watcher.FilterFileHookFunc, erro
 // events to the Events channel
 func (w *Watcher) Watch() {
 	go w.watchFsEvents()
-	go w.fsWatcher.Start(pollInterval)
+	go w.fsWatcher.Start(w.pollInterval())
+}
+
+// pollInterval returns how often to poll the filesystem so that the changes to a
+// file from one save land within the same debounce window
+func (w *Watcher) pollInterval() time.Duration {
+	if half := w.debounce / 2; half > 0 && half < pollInterval {
+		return half
+	}
+	return pollInterval
 }
 
 func (w *Watcher) watchFsEvents() {
@@ -130,7 +163,7 @@ func (w *Watcher) onEvent(event watcher.Event) bool {
 		return false
 	}
 
-	drainTimer := time.NewTimer(drainTimeout)
+	drainTimer := time.NewTimer(w.debounce)
 	defer drainTimer.Stop()
 	for {
 		select {
@@ -141,7 +174,7 @@ func (w *Watcher) onEvent(event watcher.Event) bool {
 			for _, e := range w.translateEvent(event) {
 				events[e.Path] = e
 			}
-			drainTimer.Reset(drainTimeout)
+			drainTimer.Reset(w.debounce)
 		case <-drainTimer.C:
 			for _, e := range events {
 				w.updateChecksum(e)
@@ -15,7 +15,7 @@ import (
 )
 
 func TestMain(m *testing.M) {
-	drainTimeout = 100 * time.Millisecond
+	defaultDebounce = 100 * time.Millisecond
 	pollInterval = time.Nanosecond
 	os.Exit(m.Run())
 }
@@ -26,6 +26,21 @@ func TestNewFileWatcher(t *testing.T) {
 	assert.NotNil(t, w.Events)
 }
 
+func TestNewFileWatcher_Debounce(t *testing.T) {
+	defer func(interval time.Duration) { pollInterval = interval }(pollInterval)
+	pollInterval = 500 * time.Millisecond
+
+	w := createTestWatcher(t)
+	assert.Equal(t, defaultDebounce, w.debounce)
+	assert.Equal(t, defaultDebounce/2, w.pollInterval())
+
+	e := &env.Env{Directory: filepath.Join("_testdata", "project"), Debounce: 2 * time.Second}
+	w, err := NewWatcher(e, "", map[string]string{})
+	assert.Nil(t, err)
+	assert.Equal(t, 2*time.Second, w.debounce)
+	assert.Equal(t, pollInterval, w.pollInterval())
+}
+
 func TestFileWatcher_Watch(t *testing.T) {
 	e := &env.Env{
 		Directory:    filepath.Join("_testdata", "project"),
@@ -215,7 +230,7 @@ func TestFileWatcher_debouncing(t *testing.T) {
 	}()
 	go w.watchFsEvents()
 	defer w.Stop()
-	time.Sleep(2 * drainTimeout)
+	time.Sleep(2 * w.debounce)
 	assert.Equal(t, 1, len(w.Events))
 }
 
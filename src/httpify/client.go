@@ -1,13 +1,18 @@
 package httpify
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,6 +25,9 @@ var (
 	// ErrConnectionIssue is an error that is thrown when a very specific error is
 	// returned from our http request that usually implies bad connections.
 	ErrConnectionIssue = errors.New("DNS problem while connecting to Shopify, this indicates a problem with your internet connection")
+	// ErrShopUnavailable is returned when shopify keeps responding that the store is
+	// unavailable, this usually means that shopify is down for maintenance.
+	ErrShopUnavailable = errors.New("store temporarily unavailable, shopify may be down for maintenance. Please try again later")
 	// ErrInvalidProxyURL is returned if a proxy url has been passed but is improperly formatted
 	ErrInvalidProxyURL = errors.New("invalid proxy URI")
 	httpTransport      = &http.Transport{
@@ -29,26 +37,57 @@ var (
 		Timeout: 30 * time.Second,
 	}
 	themeKitAccessURL = "https://theme-kit-access.shopifyapps.com/cli"
+	// retryBaseDelay is the delay before the first retry, it doubles for every
+	// attempt after that up to retryMaxDelay
+	retryBaseDelay = 250 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+	// unavailableRetryBaseDelay is used instead when the store is unavailable
+	// because maintenance takes longer to recover from than a failed request.
+	unavailableRetryBaseDelay = 2 * time.Second
+	// debugLog receives a line for every request attempt when debug logging is enabled
+	debugLog *log.Logger
+)
+
+const (
+	defaultMaxRetry     = 3
+	defaultAPICallLimit = 2
+	// NoTimeout can be passed as the Timeout param to disable the request timeout
+	NoTimeout time.Duration = -1
+	// NoRetries can be passed as the MaxRetries param to never retry a request
+	NoRetries = -1
+	// NoCallLimit can be passed as the APICallLimit param to not limit the requests
+	NoCallLimit = -1
 )
 
 type proxyHandler func(*http.Request) (*url.URL, error)
 
 // Params allows for a better structured input into NewClient
 type Params struct {
-	Domain   string
-	Password string
-	Proxy    string
-	Timeout  time.Duration
+	Domain      string
+	Password    string
+	AccessToken string
+	// APIKey is the username of private app credentials, when it is set the
+	// password is sent with it using basic auth.
+	APIKey  string
+	Proxy   string
+	Timeout time.Duration
+	// ConnectTimeout limits how long establishing the connection and the TLS
+	// handshake can take, Timeout still applies to the whole request.
+	ConnectTimeout time.Duration
+	MaxRetries     int
+	APICallLimit   int
 }
 
 // HTTPClient encapsulates an authenticate http client to issue theme requests
 // to Shopify
 type HTTPClient struct {
-	domain   string
-	password string
-	baseURL  *url.URL
-	limit    *ratelimiter.Limiter
-	maxRetry int
+	domain      string
+	password    string
+	accessToken string
+	apiKey      string
+	baseURL     *url.URL
+	limit       *ratelimiter.Limiter
+	maxRetry    int
 }
 
 // NewClient will create a new authenticated http client that will communicate
@@ -59,7 +98,9 @@ func NewClient(params Params) (*HTTPClient, error) {
 		return nil, err
 	}
 
-	if params.Timeout != 0 {
+	if params.Timeout == NoTimeout {
+		httpClient.Timeout = 0
+	} else if params.Timeout != 0 {
 		httpClient.Timeout = params.Timeout
 	}
 
@@ -72,39 +113,71 @@ func NewClient(params Params) (*HTTPClient, error) {
 		httpClient.Transport = httpTransport
 	}
 
+	if params.ConnectTimeout != 0 {
+		transport, ok := httpClient.Transport.(*http.Transport)
+		if !ok {
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+			httpClient.Transport = transport
+		}
+		transport.DialContext = (&net.Dialer{
+			Timeout:   params.ConnectTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+		transport.TLSHandshakeTimeout = params.ConnectTimeout
+	}
+
+	maxRetry := defaultMaxRetry
+	if params.MaxRetries == NoRetries {
+		maxRetry = 0
+	} else if params.MaxRetries > 0 {
+		maxRetry = params.MaxRetries
+	}
+
+	// the limiter is shared by every client for the same domain so the lowest
+	// call limit of those clients is used.
+	callLimit := defaultAPICallLimit
+	if params.APICallLimit == NoCallLimit {
+		callLimit = 0
+	} else if params.APICallLimit > 0 {
+		callLimit = params.APICallLimit
+	}
+
 	return &HTTPClient{
-		domain:   params.Domain,
-		password: params.Password,
-		baseURL:  baseURL,
-		limit:    ratelimiter.New(params.Domain, 4),
-		maxRetry: 5,
+		domain:      params.Domain,
+		password:    params.Password,
+		accessToken: params.AccessToken,
+		apiKey:      params.APIKey,
+		baseURL:     baseURL,
+		limit:       ratelimiter.New(params.Domain, callLimit),
+		maxRetry:    maxRetry,
 	}, nil
 }
 
 // Get will send a get request to the path provided
-func (client *HTTPClient) Get(path string, headers map[string]string) (*http.Response, error) {
-	return client.do("GET", path, nil, headers)
+func (client *HTTPClient) Get(ctx context.Context, path string, headers map[string]string) (*http.Response, error) {
+	return client.do(ctx, "GET", path, nil, headers)
 }
 
 // Post will send a Post request to the path provided and set the post body as the
 // object passed
-func (client *HTTPClient) Post(path string, body interface{}, headers map[string]string) (*http.Response, error) {
-	return client.do("POST", path, body, headers)
+func (client *HTTPClient) Post(ctx context.Context, path string, body interface{}, headers map[string]string) (*http.Response, error) {
+	return client.do(ctx, "POST", path, body, headers)
 }
 
 // Put will send a Put request to the path provided and set the post body as the
 // object passed
-func (client *HTTPClient) Put(path string, body interface{}, headers map[string]string) (*http.Response, error) {
-	return client.do("PUT", path, body, headers)
+func (client *HTTPClient) Put(ctx context.Context, path string, body interface{}, headers map[string]string) (*http.Response, error) {
+	return client.do(ctx, "PUT", path, body, headers)
 }
 
 // Delete will send a delete request to the path provided
-func (client *HTTPClient) Delete(path string, headers map[string]string) (*http.Response, error) {
-	return client.do("DELETE", path, nil, headers)
+func (client *HTTPClient) Delete(ctx context.Context, path string, headers map[string]string) (*http.Response, error) {
+	return client.do(ctx, "DELETE", path, nil, headers)
 }
 
-// do will issue an authenticated json request to shopify.
-func (client *HTTPClient) do(method, path string, body interface{}, headers map[string]string) (*http.Response, error) {
+// do will issue an authenticated json request to shopify. Cancelling ctx aborts
+// the request and any retries that are left.
+func (client *HTTPClient) do(ctx context.Context, method, path string, body interface{}, headers map[string]string) (*http.Response, error) {
 	appBaseURL := client.baseURL.String()
 
 	// redirect to Theme Access
@@ -118,7 +191,11 @@ func (client *HTTPClient) do(method, path string, body interface{}, headers map[
 		return nil, err
 	}
 
-	req.Header.Add("X-Shopify-Access-Token", client.password)
+	if client.apiKey != "" && client.accessToken == "" {
+		req.SetBasicAuth(client.apiKey, client.password)
+	} else {
+		req.Header.Add("X-Shopify-Access-Token", client.token())
+	}
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("User-Agent", fmt.Sprintf("go/themekit (%s; %s; %s)", runtime.GOOS, runtime.GOARCH, release.ThemeKitVersion.String()))
@@ -129,10 +206,25 @@ func (client *HTTPClient) do(method, path string, body interface{}, headers map[
 		req.Header.Add(label, value)
 	}
 
-	return client.doWithRetry(req, body)
+	return client.doWithRetry(ctx, req, body)
+}
+
+// token returns the admin api access token if one was set, otherwise the legacy
+// theme password is used.
+func (client *HTTPClient) token() string {
+	if client.accessToken != "" {
+		return client.accessToken
+	}
+	return client.password
+}
+
+// SetDebugLog sets a logger that will receive a line for every request attempt.
+// Passing nil disables request logging.
+func SetDebugLog(logger *log.Logger) {
+	debugLog = logger
 }
 
-func (client *HTTPClient) doWithRetry(req *http.Request, body interface{}) (*http.Response, error) {
+func (client *HTTPClient) doWithRetry(ctx context.Context, req *http.Request, body interface{}) (*http.Response, error) {
 	var (
 		bodyData []byte
 		resp     *http.Response
@@ -147,18 +239,59 @@ func (client *HTTPClient) doWithRetry(req *http.Request, body interface{}) (*htt
 	}
 
 	for attempt := 0; attempt <= client.maxRetry; attempt++ {
-		resp, err = client.limit.GateReq(httpClient, req, bodyData)
-		if err == nil && resp.StatusCode >= 100 && resp.StatusCode < 500 {
+		resp, err = client.limit.GateReq(ctx, httpClient, req, bodyData)
+		logAttempt(req, resp, err, attempt)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		} else if err == nil && resp.StatusCode >= 100 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
 			return resp, nil
 		} else if err != nil && strings.Contains(err.Error(), "no such host") {
 			return nil, ErrConnectionIssue
 		}
-		time.Sleep(time.Duration(attempt) * time.Second)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if attempt < client.maxRetry {
+			select {
+			case <-time.After(retryDelay(attempt, resp)):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	if err == nil && resp.StatusCode == http.StatusServiceUnavailable {
+		return nil, ErrShopUnavailable
+	} else if err == nil {
+		err = fmt.Errorf("server responded with %v", resp.Status)
 	}
 
 	return nil, fmt.Errorf("request failed after %v retries with error: %v", client.maxRetry, err)
 }
 
+// retryDelay will honor the Retry-After header if the server sent one, otherwise
+// it will back off exponentially with some jitter so that concurrent requests
+// do not all retry at the same moment. The rate limiter has already paused for
+// the Retry-After of a 429 so only the backoff is added for those.
+func retryDelay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil && resp.StatusCode != http.StatusTooManyRequests {
+		if after, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil && after > 0 {
+			return time.Duration(after * float64(time.Second))
+		}
+	}
+
+	base := retryBaseDelay
+	if resp != nil && resp.StatusCode == http.StatusServiceUnavailable {
+		base = unavailableRetryBaseDelay
+	}
+
+	delay := base << uint(attempt)
+	if delay <= 0 || delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
 func parseBaseURL(domain string) (*url.URL, error) {
 	u, err := url.Parse(domain)
 	if err != nil {
@@ -169,3 +302,14 @@ func parseBaseURL(domain string) (*url.URL, error) {
 	}
 	return u, nil
 }
+
+func logAttempt(req *http.Request, resp *http.Response, err error, attempt int) {
+	if debugLog == nil {
+		return
+	}
+	status := "error: " + fmt.Sprint(err)
+	if err == nil {
+		status = resp.Status
+	}
+	debugLog.Printf("%s %s status=%v retry=%v", req.Method, req.URL, status, attempt)
+}
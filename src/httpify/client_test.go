@@ -1,9 +1,12 @@
 package httpify
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"runtime"
@@ -13,6 +16,7 @@ import (
 
 	"github.com/Shopify/themekit/src/release"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
 )
 
 func TestNewClient(t *testing.T) {
@@ -32,6 +36,55 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+func TestClient_AccessToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "shpat_token", r.Header.Get("X-Shopify-Access-Token"))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Params{Domain: server.URL, Password: "secret_password", AccessToken: "shpat_token"})
+	assert.Nil(t, err)
+	client.baseURL.Scheme = "http"
+
+	_, err = client.Get(context.Background(), "/assets.json", nil)
+	assert.Nil(t, err)
+}
+
+func TestClient_debugLog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	out := bytes.NewBufferString("")
+	SetDebugLog(log.New(out, "", 0))
+	defer SetDebugLog(nil)
+
+	client, err := NewClient(Params{Domain: server.URL, Password: "secret_password"})
+	assert.Nil(t, err)
+	client.baseURL.Scheme = "http"
+
+	_, err = client.Get(context.Background(), "/assets.json", nil)
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "GET "+server.URL+"/assets.json status=200 OK retry=0")
+}
+
+func TestClient_APIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "api_key", username)
+		assert.Equal(t, "secret_password", password)
+		assert.Equal(t, "", r.Header.Get("X-Shopify-Access-Token"))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Params{Domain: server.URL, Password: "secret_password", APIKey: "api_key"})
+	assert.Nil(t, err)
+	client.baseURL.Scheme = "http"
+
+	_, err = client.Get(context.Background(), "/assets.json", nil)
+	assert.Nil(t, err)
+}
+
 func TestClient_do(t *testing.T) {
 	body := map[string]interface{}{"key": "main.js", "value": "alert('this is javascript');"}
 
@@ -64,11 +117,11 @@ func TestClient_do(t *testing.T) {
 	assert.NotNil(t, client)
 	assert.Nil(t, err)
 
-	resp, err := client.Post("/assets.json", body, map[string]string{"X-Custom-Header": "Checksum"})
+	resp, err := client.Post(context.Background(), "/assets.json", body, map[string]string{"X-Custom-Header": "Checksum"})
 	assert.Nil(t, err)
 	assert.NotNil(t, resp)
 
-	resp, err = client.Put("/assets.json", body, map[string]string{"X-Custom-Header": "Checksum"})
+	resp, err = client.Put(context.Background(), "/assets.json", body, map[string]string{"X-Custom-Header": "Checksum"})
 	assert.Nil(t, err)
 	assert.NotNil(t, resp)
 
@@ -88,11 +141,11 @@ func TestClient_do(t *testing.T) {
 	})
 	client.baseURL.Scheme = "http"
 
-	resp, err = client.Get("/assets.json", map[string]string{"X-Custom-Header": "Foo"})
+	resp, err = client.Get(context.Background(), "/assets.json", map[string]string{"X-Custom-Header": "Foo"})
 	assert.Nil(t, err)
 	assert.NotNil(t, resp)
 
-	resp, err = client.Delete("/assets.json", map[string]string{"X-Custom-Header": "Foo"})
+	resp, err = client.Delete(context.Background(), "/assets.json", map[string]string{"X-Custom-Header": "Foo"})
 	assert.Nil(t, err)
 	assert.NotNil(t, resp)
 
@@ -120,7 +173,7 @@ func TestClient_do(t *testing.T) {
 	assert.NotNil(t, client)
 	assert.Nil(t, err)
 
-	_, err = client.do("POST", "/assets.json", body, nil)
+	_, err = client.do(context.Background(), "POST", "/assets.json", body, nil)
 	assert.Nil(t, err)
 	server.Close()
 
@@ -138,7 +191,7 @@ func TestClient_do(t *testing.T) {
 	assert.NotNil(t, client)
 	assert.Nil(t, err)
 
-	_, err = client.do("POST", "/assets.json", body, nil)
+	_, err = client.do(context.Background(), "POST", "/assets.json", body, nil)
 	assert.Contains(t, err.Error(), "request failed after 1 retries", server.URL)
 	server.Close()
 
@@ -158,7 +211,7 @@ func TestClient_do(t *testing.T) {
 	assert.NotNil(t, client)
 	assert.Nil(t, err)
 
-	resp, err = client.Post("/assets.json", body, map[string]string{"X-Custom-Header": "Checksum"})
+	resp, err = client.Post(context.Background(), "/assets.json", body, map[string]string{"X-Custom-Header": "Checksum"})
 	assert.Nil(t, err)
 	assert.NotNil(t, resp)
 
@@ -180,13 +233,127 @@ func TestClient_do(t *testing.T) {
 	assert.NotNil(t, client)
 	assert.Nil(t, err)
 
-	resp, err = client.Post("/assets.json", body, map[string]string{"X-Custom-Header": "Checksum"})
+	resp, err = client.Post(context.Background(), "/assets.json", body, map[string]string{"X-Custom-Header": "Checksum"})
 	assert.Nil(t, err)
 	assert.NotNil(t, resp)
 
 	server.Close()
 }
 
+func TestClient_doRetries(t *testing.T) {
+	retryBaseDelay = time.Millisecond
+	defer func() { retryBaseDelay = 250 * time.Millisecond }()
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(Params{Domain: server.URL, MaxRetries: 3})
+	client.baseURL.Scheme = "http"
+	assert.Equal(t, 3, client.maxRetry)
+	resp, err := client.Get(context.Background(), "/assets.json", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 3, requests)
+
+	requests = 0
+	client.maxRetry = 1
+	_, err = client.Get(context.Background(), "/assets.json", nil)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "request failed after 1 retries with error: server responded with 502 Bad Gateway")
+	}
+	assert.Equal(t, 2, requests)
+
+	client, _ = NewClient(Params{Domain: server.URL})
+	assert.Equal(t, 3, client.maxRetry)
+
+	requests = 0
+	client, _ = NewClient(Params{Domain: server.URL, MaxRetries: NoRetries})
+	client.baseURL.Scheme = "http"
+	assert.Equal(t, 0, client.maxRetry)
+	_, err = client.Get(context.Background(), "/assets.json", nil)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "request failed after 0 retries")
+	}
+	assert.Equal(t, 1, requests)
+}
+
+func TestClient_doRetriesTooManyRequests(t *testing.T) {
+	retryBaseDelay = time.Millisecond
+	defer func() { retryBaseDelay = 250 * time.Millisecond }()
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(Params{Domain: server.URL, MaxRetries: 2})
+	client.baseURL.Scheme = "http"
+	_, err := client.Get(context.Background(), "/assets.json", nil)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "request failed after 2 retries with error: server responded with 429 Too Many Requests")
+	}
+	assert.Equal(t, 3, requests)
+}
+
+func TestClient_doUnavailable(t *testing.T) {
+	unavailableRetryBaseDelay = time.Millisecond
+	defer func() { unavailableRetryBaseDelay = 2 * time.Second }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("<html>maintenance</html>"))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(Params{Domain: server.URL, MaxRetries: 1})
+	client.baseURL.Scheme = "http"
+	_, err := client.Get(context.Background(), "/assets.json", nil)
+	assert.Equal(t, ErrShopUnavailable, err)
+}
+
+func TestRetryDelay(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"2"}}}
+	assert.Equal(t, 2*time.Second, retryDelay(0, resp))
+
+	for attempt := 0; attempt < 10; attempt++ {
+		maxDelay := retryBaseDelay << uint(attempt)
+		if maxDelay > retryMaxDelay {
+			maxDelay = retryMaxDelay
+		}
+		delay := retryDelay(attempt, nil)
+		assert.True(t, delay >= maxDelay/2 && delay <= maxDelay, fmt.Sprintf("attempt %v delay %v", attempt, delay))
+	}
+
+	tooMany := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"2"}}}
+	assert.True(t, retryDelay(0, tooMany) <= retryBaseDelay)
+
+	unavailable := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+	delay := retryDelay(0, unavailable)
+	assert.True(t, delay >= unavailableRetryBaseDelay/2 && delay <= unavailableRetryBaseDelay, fmt.Sprintf("unavailable delay %v", delay))
+}
+
+func TestClientAPICallLimit(t *testing.T) {
+	client, err := NewClient(Params{Domain: "https://limited.myshopify.com", APICallLimit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, rate.Limit(2), client.limit.Limit())
+
+	client, err = NewClient(Params{Domain: "https://default.myshopify.com"})
+	assert.Nil(t, err)
+	assert.Equal(t, rate.Limit(2), client.limit.Limit())
+
+	client, err = NewClient(Params{Domain: "https://unlimited.myshopify.com", APICallLimit: NoCallLimit})
+	assert.Nil(t, err)
+	assert.Equal(t, rate.Inf, client.limit.Limit())
+}
+
 func TestGenerateHTTPAdapter(t *testing.T) {
 	NewClient(Params{
 		Domain:  "https://shop.myshopify.com",
@@ -196,6 +363,26 @@ func TestGenerateHTTPAdapter(t *testing.T) {
 
 	NewClient(Params{Domain: "https://shop.myshopify.com"})
 	assert.Equal(t, httpClient.Timeout, 60*time.Second)
+
+	NewClient(Params{Domain: "https://shop.myshopify.com", Timeout: NoTimeout})
+	assert.Equal(t, httpClient.Timeout, time.Duration(0))
+}
+
+func TestConnectTimeout(t *testing.T) {
+	originalTransport := httpClient.Transport
+	defer func() { httpClient.Transport = originalTransport }()
+	httpClient.Transport = nil
+
+	_, err := NewClient(Params{
+		Domain:         "https://shop.myshopify.com",
+		ConnectTimeout: 5 * time.Second,
+	})
+	assert.Nil(t, err)
+	if transport, ok := httpClient.Transport.(*http.Transport); assert.True(t, ok) {
+		assert.Equal(t, 5*time.Second, transport.TLSHandshakeTimeout)
+		assert.NotNil(t, transport.DialContext)
+		assert.True(t, transport.TLSClientConfig == nil || !transport.TLSClientConfig.InsecureSkipVerify)
+	}
 }
 
 func TestProxyConfig(t *testing.T) {
@@ -205,6 +392,7 @@ func TestProxyConfig(t *testing.T) {
 		{proxyURL: ""},
 		{proxyURL: "http//localhost:3000", err: "invalid proxy URI"},
 		{proxyURL: "http://127.0.0.1:8080"},
+		{proxyURL: "socks5://127.0.0.1:1080"},
 	}
 
 	for _, testcase := range testcases {
@@ -241,3 +429,20 @@ func TestParseBaseUrl(t *testing.T) {
 		}
 	}
 }
+
+func TestClient_doCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(Params{Domain: server.URL, MaxRetries: 3})
+	client.baseURL.Scheme = "http"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	started := time.Now()
+	_, err := client.Get(ctx, "/assets.json", nil)
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, time.Since(started) < retryBaseDelay)
+}
@@ -5,13 +5,18 @@ import (
 	"context"
 	"errors"
 	"golang.org/x/time/rate"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 )
 
-var domainLimitMap = make(map[string]*Limiter)
+var (
+	domainLimitMu  sync.Mutex
+	domainLimitMap = make(map[string]*Limiter)
+)
 
 // Limiter keeps track of an api rate limit and wont let you pass the limit
 type Limiter struct {
@@ -23,43 +28,105 @@ type Limiter struct {
 	locked    bool
 }
 
-// New creates a new call rate limiter for a single domain
+// New creates a new call rate limiter for a single domain, a reqPerSec of 0 does
+// not limit the requests. The limiter is shared by every caller for the domain
+// because they all use the same api bucket, so the lowest limit asked for wins.
 func New(domain string, reqPerSec int) *Limiter {
-	if _, ok := domainLimitMap[domain]; !ok {
-		everySecond := rate.Every(time.Second / time.Duration(reqPerSec))
+	everySecond := rate.Inf
+	if reqPerSec > 0 {
+		everySecond = rate.Every(time.Second / time.Duration(reqPerSec))
+	}
+
+	domainLimitMu.Lock()
+	defer domainLimitMu.Unlock()
+	limiter, ok := domainLimitMap[domain]
+	if !ok {
 		ctx, cancel := context.WithCancel(context.Background())
-		domainLimitMap[domain] = &Limiter{
+		limiter = &Limiter{
 			perSecond: everySecond,
 			rate:      rate.NewLimiter(everySecond, reqPerSec),
 			ctx:       ctx,
 			cancel:    cancel,
 		}
+		domainLimitMap[domain] = limiter
+	} else if everySecond < limiter.perSecond {
+		limiter.perSecond = everySecond
+		limiter.rate.SetBurst(reqPerSec)
+		if !limiter.locked {
+			limiter.rate.SetLimit(everySecond)
+		}
 	}
-	return domainLimitMap[domain]
+	return limiter
 }
 
-// GateReq will make the http request but will force it to comply with concurrent limits,
-// rate limits, and it will also retry requests that receive 429.
-// When a 429 occurs, it will cancel all inflight requests and pauses, so that the requests
-// dont continue to batter the server and cause bot detection
-func (limiter *Limiter) GateReq(client *http.Client, origReq *http.Request, body []byte) (*http.Response, error) {
-	limiter.rate.Wait(context.Background())
-	req := origReq.WithContext(limiter.ctx)
+// Limit returns the number of requests per second that this limiter allows
+func (limiter *Limiter) Limit() rate.Limit {
+	return limiter.perSecond
+}
+
+// GateReq will make the http request but will force it to comply with concurrent limits
+// and rate limits. When a 429 occurs, it will cancel all inflight requests and pause
+// for the Retry-After time, so that the requests dont continue to batter the server
+// and cause bot detection. The 429 response is then returned so that the caller can
+// decide whether to retry it. Cancelling ctx aborts the request, even while it is
+// waiting on the limit.
+func (limiter *Limiter) GateReq(ctx context.Context, client *http.Client, origReq *http.Request, body []byte) (*http.Response, error) {
+	if err := limiter.rate.Wait(ctx); err != nil {
+		return nil, err
+	}
+	reqCtx, cancel := context.WithCancel(ctx)
+	stop := make(chan struct{})
+	go func(paused context.Context) {
+		select {
+		case <-paused.Done():
+			cancel()
+		case <-stop:
+		}
+	}(limiter.ctx)
+	req := origReq.WithContext(reqCtx)
 	// reset the body when non-nil for every request (rewind)
 	if len(body) > 0 {
 		req.Body = ioutil.NopCloser(bytes.NewBuffer(body))
 	}
 	resp, err := client.Do(req)
+	close(stop)
+	if err != nil {
+		cancel()
+	}
+
 	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
 		limiter.retryAfter(resp.Header.Get("Retry-After"))
-		return limiter.GateReq(client, origReq, body)
+	} else if ctx.Err() != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return nil, ctx.Err()
 	} else if errors.Is(err, context.Canceled) {
-		<-limiter.waiting
-		return limiter.GateReq(client, origReq, body)
+		select {
+		case <-limiter.waiting:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return limiter.GateReq(ctx, client, origReq, body)
+	}
+	if resp != nil {
+		// the request context has to outlive this call until the body is read
+		resp.Body = cancelBody{ReadCloser: resp.Body, cancel: cancel}
 	}
 	return resp, err
 }
 
+// cancelBody releases the request context once the response body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (body cancelBody) Close() error {
+	defer body.cancel()
+	return body.ReadCloser.Close()
+}
+
 func (limiter *Limiter) retryAfter(header string) {
 	limiter.lock()
 	defer limiter.unlock()
@@ -1,6 +1,9 @@
 package ratelimiter
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -16,6 +19,21 @@ func TestRateLimiterForDomain(t *testing.T) {
 	assert.NotEqual(t, limiter2, limiter3)
 }
 
+func TestRateLimiterLowestLimit(t *testing.T) {
+	limiter := New("lowest.com", 4)
+	assert.Equal(t, rate.Limit(4), limiter.Limit())
+	New("lowest.com", 2)
+	assert.Equal(t, rate.Limit(2), limiter.Limit())
+	assert.Equal(t, rate.Limit(2), limiter.rate.Limit())
+	New("lowest.com", 3)
+	assert.Equal(t, rate.Limit(2), limiter.Limit())
+
+	unlimited := New("unlimited.com", 0)
+	assert.Equal(t, rate.Inf, unlimited.Limit())
+	New("unlimited.com", 2)
+	assert.Equal(t, rate.Limit(2), unlimited.Limit())
+}
+
 func TestRateLimiterLockUnlock(t *testing.T) {
 	limiter := New("domain.com", 1)
 	assert.Equal(t, limiter.rate.Limit(), rate.Limit(1))
@@ -35,3 +53,20 @@ func TestRateLimiterRetryAfter(t *testing.T) {
 	after := time.Now()
 	assert.True(t, after.After(expected) || after.Equal(expected))
 }
+
+func TestRateLimiterGateReqCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	limiter := New("cancel.com", 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err := limiter.GateReq(ctx, server.Client(), req, nil)
+	assert.Equal(t, context.Canceled, err)
+
+	_, err = limiter.GateReq(ctx, server.Client(), req, nil)
+	assert.Equal(t, context.Canceled, err)
+}
@@ -2,6 +2,7 @@
 
 package mocks
 
+import context "context"
 import http "net/http"
 import mock "github.com/stretchr/testify/mock"
 
@@ -10,13 +11,13 @@ type HttpAdapter struct {
 	mock.Mock
 }
 
-// Delete provides a mock function with given fields: _a0, _a1
-func (_m *HttpAdapter) Delete(_a0 string, _a1 map[string]string) (*http.Response, error) {
-	ret := _m.Called(_a0, _a1)
+// Delete provides a mock function with given fields: _a0, _a1, _a2
+func (_m *HttpAdapter) Delete(_a0 context.Context, _a1 string, _a2 map[string]string) (*http.Response, error) {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 *http.Response
-	if rf, ok := ret.Get(0).(func(string, map[string]string) *http.Response); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]string) *http.Response); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*http.Response)
@@ -24,8 +25,8 @@ func (_m *HttpAdapter) Delete(_a0 string, _a1 map[string]string) (*http.Response
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, map[string]string) error); ok {
-		r1 = rf(_a0, _a1)
+	if rf, ok := ret.Get(1).(func(context.Context, string, map[string]string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -33,13 +34,13 @@ func (_m *HttpAdapter) Delete(_a0 string, _a1 map[string]string) (*http.Response
 	return r0, r1
 }
 
-// Get provides a mock function with given fields: _a0, _a1
-func (_m *HttpAdapter) Get(_a0 string, _a1 map[string]string) (*http.Response, error) {
-	ret := _m.Called(_a0, _a1)
+// Get provides a mock function with given fields: _a0, _a1, _a2
+func (_m *HttpAdapter) Get(_a0 context.Context, _a1 string, _a2 map[string]string) (*http.Response, error) {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 *http.Response
-	if rf, ok := ret.Get(0).(func(string, map[string]string) *http.Response); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]string) *http.Response); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*http.Response)
@@ -47,8 +48,8 @@ func (_m *HttpAdapter) Get(_a0 string, _a1 map[string]string) (*http.Response, e
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, map[string]string) error); ok {
-		r1 = rf(_a0, _a1)
+	if rf, ok := ret.Get(1).(func(context.Context, string, map[string]string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -56,13 +57,13 @@ func (_m *HttpAdapter) Get(_a0 string, _a1 map[string]string) (*http.Response, e
 	return r0, r1
 }
 
-// Post provides a mock function with given fields: _a0, _a1, _a2
-func (_m *HttpAdapter) Post(_a0 string, _a1 interface{}, _a2 map[string]string) (*http.Response, error) {
-	ret := _m.Called(_a0, _a1, _a2)
+// Post provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *HttpAdapter) Post(_a0 context.Context, _a1 string, _a2 interface{}, _a3 map[string]string) (*http.Response, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
 
 	var r0 *http.Response
-	if rf, ok := ret.Get(0).(func(string, interface{}, map[string]string) *http.Response); ok {
-		r0 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}, map[string]string) *http.Response); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*http.Response)
@@ -70,8 +71,8 @@ func (_m *HttpAdapter) Post(_a0 string, _a1 interface{}, _a2 map[string]string)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, interface{}, map[string]string) error); ok {
-		r1 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(1).(func(context.Context, string, interface{}, map[string]string) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -79,13 +80,13 @@ func (_m *HttpAdapter) Post(_a0 string, _a1 interface{}, _a2 map[string]string)
 	return r0, r1
 }
 
-// Put provides a mock function with given fields: _a0, _a1, _a2
-func (_m *HttpAdapter) Put(_a0 string, _a1 interface{}, _a2 map[string]string) (*http.Response, error) {
-	ret := _m.Called(_a0, _a1, _a2)
+// Put provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *HttpAdapter) Put(_a0 context.Context, _a1 string, _a2 interface{}, _a3 map[string]string) (*http.Response, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
 
 	var r0 *http.Response
-	if rf, ok := ret.Get(0).(func(string, interface{}, map[string]string) *http.Response); ok {
-		r0 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}, map[string]string) *http.Response); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*http.Response)
@@ -93,8 +94,8 @@ func (_m *HttpAdapter) Put(_a0 string, _a1 interface{}, _a2 map[string]string) (
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, interface{}, map[string]string) error); ok {
-		r1 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(1).(func(context.Context, string, interface{}, map[string]string) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		r1 = ret.Error(1)
 	}
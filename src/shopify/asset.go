@@ -10,8 +10,12 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/Shopify/themekit/src/env"
 	"github.com/Shopify/themekit/src/file"
@@ -28,21 +32,48 @@ type Asset struct {
 	UpdatedAt   string `json:"updated_at,omitempty"`
 }
 
+const (
+	ignoreDirectiveScanSize = 512
+	// readConcurrency is the number of local files that are read at the same time
+	readConcurrency = 8
+	// MaxAssetSize is the largest file that shopify accepts as a theme asset
+	MaxAssetSize = 20 * 1024 * 1024
+)
+
 var (
+	binaryExtensions = map[string]bool{
+		".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".ico": true,
+		".woff": true, ".woff2": true, ".ttf": true, ".otf": true, ".eot": true,
+		".mp4": true, ".webm": true, ".mov": true, ".mp3": true, ".pdf": true, ".zip": true,
+	}
+	textExtensions = map[string]bool{
+		".liquid": true, ".json": true, ".js": true, ".css": true, ".scss": true,
+		".svg": true, ".txt": true, ".html": true, ".md": true,
+	}
+	// ignoreDirective marks a file that should never be uploaded by themekit
+	ignoreDirective = []byte("themekit:ignore")
 	// ErrAssetIsDir is the error returned if you try and load a directory with ReadAsset
 	ErrAssetIsDir = errors.New("requested asset is a directory")
 )
 
+// NormalizeKey will convert a path as it was typed by a user into an asset key.
+// Keys are always slash separated and relative to the theme root so leading and
+// trailing slashes are removed and windows separators are converted.
+func NormalizeKey(key string) string {
+	key = strings.Replace(key, "\\", "/", -1)
+	return strings.TrimPrefix(path.Clean("/"+key), "/")
+}
+
 // ReadAsset will read a single asset from disk
 func ReadAsset(e *env.Env, filename string) (Asset, error) {
-	return readAsset(e.Directory, filename)
+	return readAsset(e.Directory, filename, e.ContentTypes)
 }
 
 // FindAssets will load all assets for paths passed in, this also means that it will
 // read directories recursively. If no paths are passed in then the whole project
 // directory will be read
 func FindAssets(e *env.Env, paths ...string) (assets []Asset, err error) {
-	filter, err := file.NewFilter(e.Directory, e.IgnoredFiles, e.Ignores)
+	filter, err := file.NewFilter(e.Directory, e.IgnoredFiles, e.Ignores, e.IncludedFiles)
 	if err != nil {
 		return []Asset{}, err
 	}
@@ -52,7 +83,8 @@ func FindAssets(e *env.Env, paths ...string) (assets []Asset, err error) {
 	}
 
 	for _, path := range paths {
-		asset, err := readAsset(e.Directory, path)
+		path = NormalizeKey(path)
+		asset, err := readAsset(e.Directory, path, e.ContentTypes)
 		if err == ErrAssetIsDir {
 			dirAssets, err := loadAssetsFromDirectory(e, path, filter.Match)
 			if err != nil {
@@ -98,6 +130,54 @@ func (asset Asset) Write(directory string) error {
 	return err
 }
 
+// Size returns the number of bytes in the contents of the asset
+func (asset Asset) Size() int {
+	if asset.Attachment != "" {
+		padding := len(asset.Attachment) - len(strings.TrimRight(asset.Attachment, "="))
+		return base64.StdEncoding.DecodedLen(len(asset.Attachment)) - padding
+	}
+	return len(asset.Value)
+}
+
+// OverMaxFileSize returns true if the asset is larger than the max_file_size of
+// the environment, these files are skipped instead of uploaded.
+func (asset Asset) OverMaxFileSize(e *env.Env) bool {
+	return e.MaxFileSize > 0 && asset.Size() > e.MaxFileSize
+}
+
+// Validate returns an error if shopify would reject the asset because of its size
+// so that it is reported clearly before the upload is attempted.
+func (asset Asset) Validate() error {
+	if asset.Size() > MaxAssetSize {
+		return fmt.Errorf("%s is %v bytes which is larger than the %v bytes shopify allows for an asset", asset.Key, asset.Size(), MaxAssetSize)
+	}
+	return nil
+}
+
+// PreserveModTime will set the modification time of the written asset to the time
+// it was last updated on shopify so that it is not seen as a newer local change.
+// Nothing is changed if the asset has no updated at time.
+func (asset Asset) PreserveModTime(directory string) error {
+	updatedAt, err := time.Parse(time.RFC3339, asset.UpdatedAt)
+	if err != nil {
+		return nil
+	}
+	return os.Chtimes(filepath.Join(directory, asset.Key), updatedAt, updatedAt)
+}
+
+// Ignored will return true if the asset has the themekit:ignore directive near the
+// top of its contents. Only the first few bytes of text assets are scanned.
+func (asset Asset) Ignored() bool {
+	if asset.Attachment != "" {
+		return false
+	}
+	head := []byte(asset.Value)
+	if len(head) > ignoreDirectiveScanSize {
+		head = head[:ignoreDirectiveScanSize]
+	}
+	return bytes.Contains(head, ignoreDirective)
+}
+
 func (asset Asset) contents() ([]byte, error) {
 	var data []byte
 	var err error
@@ -127,29 +207,92 @@ func assetsToFilenames(assets []Asset) []string {
 
 func loadAssetsFromDirectory(e *env.Env, dir string, ignore func(path string) bool) (assets []Asset, err error) {
 	var root = e.Directory
-	err = filepath.Walk(filepath.Join(root, dir), func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
+	// directories that have been walked, so that symlink loops are only walked once
+	visited := []os.FileInfo{}
+	keys := []string{}
+
+	var walk func(walkRoot, keyRoot string) error
+	walk = func(walkRoot, keyRoot string) error {
+		return filepath.Walk(walkRoot, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				for _, seen := range visited {
+					if os.SameFile(seen, info) {
+						return filepath.SkipDir
+					}
+				}
+				visited = append(visited, info)
+				return nil
+			}
+			rel, err := filepath.Rel(walkRoot, path)
+			if err != nil {
+				return err
+			}
+			assetKey := filepath.ToSlash(filepath.Join(keyRoot, rel))
+			if e.FollowSymlinks && info.Mode()&os.ModeSymlink != 0 {
+				if target, statErr := os.Stat(path); statErr == nil && target.IsDir() {
+					realPath, err := filepath.EvalSymlinks(path)
+					if err != nil {
+						return err
+					}
+					return walk(realPath, assetKey)
+				}
+			}
+			if !ignore(assetKey) {
+				keys = append(keys, assetKey)
+			}
 			return nil
-		}
-		assetKey, err := filepath.Rel(root, path)
-		if err != nil {
-			return err
-		}
-		assetKey = filepath.ToSlash(assetKey)
-		if !ignore(assetKey) {
-			var asset, _ = ReadAsset(e, assetKey) // TODO handle error
+		})
+	}
+
+	if err = walk(filepath.Join(root, dir), dir); err != nil {
+		return assets, err
+	}
+	return readAssets(e, keys)
+}
+
+// readAssets will read the assets for the keys with a bounded number of concurrent
+// reads. The assets are returned in the same order as the keys, and every file that
+// could not be read is reported in the error.
+func readAssets(e *env.Env, keys []string) ([]Asset, error) {
+	results := make([]Asset, len(keys))
+	errs := make([]error, len(keys))
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, readConcurrency)
+	for i, key := range keys {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, key string) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			results[i], errs[i] = ReadAsset(e, key)
+		}(i, key)
+	}
+	wg.Wait()
+
+	assets := []Asset{}
+	failed := []string{}
+	for i, asset := range results {
+		if errs[i] == nil {
 			assets = append(assets, asset)
+		} else if errs[i] != ErrAssetIsDir {
+			// symlinked directories that are not followed are left out
+			failed = append(failed, fmt.Sprintf("%s (%s)", keys[i], errs[i]))
 		}
-		return nil
-	})
-	return
+	}
+	if len(failed) > 0 {
+		return assets, fmt.Errorf("could not read %s", strings.Join(failed, ", "))
+	}
+	return assets, nil
 }
 
-func readAsset(root, filename string) (asset Asset, err error) {
-	path := filepath.Join(root, filename)
+func readAsset(root, filename string, contentTypes map[string]string) (asset Asset, err error) {
+	path := filepath.Join(root, filepath.FromSlash(NormalizeKey(filename)))
 
 	key, err := filepath.Rel(root, path)
 	if err != nil {
@@ -177,17 +320,55 @@ func readAsset(root, filename string) (asset Asset, err error) {
 		return Asset{}, fmt.Errorf("readAsset: %s", err)
 	}
 
-	contentType := http.DetectContentType(buffer)
-	if strings.Contains(contentType, "text") {
-		asset.Value = string(buffer)
-		asset.Checksum = calculateTextChecksum(asset.Value, filepath.Ext(asset.Key) == ".json")
-	} else {
+	asset.ContentType = contentTypeFor(asset.Key, contentTypes)
+	if isBinaryAsset(asset.Key, asset.ContentType, buffer) {
 		asset.Attachment = base64.StdEncoding.EncodeToString(buffer)
 		asset.Checksum = calculateByteArrayChecksum(buffer)
+	} else {
+		asset.Value = string(buffer)
+		asset.Checksum = calculateTextChecksum(asset.Value, filepath.Ext(asset.Key) == ".json")
 	}
 	return asset, nil
 }
 
+// contentTypeFor returns the configured content type for the extension of the key
+// or an empty string if the default detection should be used.
+func contentTypeFor(key string, contentTypes map[string]string) string {
+	ext := strings.ToLower(filepath.Ext(key))
+	for configured, contentType := range contentTypes {
+		if strings.ToLower("."+strings.TrimPrefix(configured, ".")) == ext {
+			return contentType
+		}
+	}
+	return ""
+}
+
+// isBinaryAsset decides if an asset has to be sent as a base64 attachment. A
+// configured content type decides first, then known binary extensions are always
+// attachments, known text extensions are values as long as they are valid utf8,
+// and anything else is decided by sniffing the content.
+func isBinaryAsset(key, contentType string, contents []byte) bool {
+	ext := strings.ToLower(filepath.Ext(key))
+	if contentType != "" {
+		return !isTextContentType(contentType)
+	} else if binaryExtensions[ext] {
+		return true
+	} else if textExtensions[ext] {
+		return !utf8.Valid(contents)
+	}
+	return !strings.Contains(http.DetectContentType(contents), "text")
+}
+
+func isTextContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, text := range []string{"text/", "json", "javascript", "xml"} {
+		if strings.Contains(contentType, text) {
+			return true
+		}
+	}
+	return false
+}
+
 func calculateTextChecksum(value string, isJSON bool) (checksum string) {
 	if isJSON {
 		buf := new(bytes.Buffer)
@@ -2,10 +2,14 @@ package shopify
 
 import (
 	"encoding/base64"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -66,6 +70,47 @@ func TestAsset_Write(t *testing.T) {
 	os.RemoveAll(testDir)
 }
 
+func TestAsset_Size(t *testing.T) {
+	assert.Equal(t, 5, Asset{Value: "hello"}.Size())
+	assert.Equal(t, 0, Asset{}.Size())
+	assert.Equal(t, 3, Asset{Attachment: base64.StdEncoding.EncodeToString([]byte("abc"))}.Size())
+	assert.Equal(t, 4, Asset{Attachment: base64.StdEncoding.EncodeToString([]byte("abcd"))}.Size())
+	assert.Equal(t, 5, Asset{Attachment: base64.StdEncoding.EncodeToString([]byte("abcde"))}.Size())
+}
+
+func TestAsset_OverMaxFileSize(t *testing.T) {
+	asset := Asset{Key: "assets/app.js.map", Value: "0123456789"}
+	assert.False(t, asset.OverMaxFileSize(&env.Env{}))
+	assert.False(t, asset.OverMaxFileSize(&env.Env{MaxFileSize: 10}))
+	assert.True(t, asset.OverMaxFileSize(&env.Env{MaxFileSize: 9}))
+}
+
+func TestAsset_Validate(t *testing.T) {
+	assert.Nil(t, Asset{Key: "assets/app.js", Value: "var a;"}.Validate())
+	err := Asset{Key: "assets/huge.js", Value: strings.Repeat("a", MaxAssetSize+1)}.Validate()
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "assets/huge.js is 20971521 bytes which is larger than the 20971520 bytes shopify allows")
+	}
+}
+
+func TestAsset_PreserveModTime(t *testing.T) {
+	testDir := filepath.Join("_testdata", "writeto")
+	os.Mkdir(testDir, 0755)
+	defer os.RemoveAll(testDir)
+
+	updatedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	asset := Asset{Key: "blah.txt", UpdatedAt: updatedAt.Format(time.RFC3339)}
+	assert.Nil(t, asset.Write(testDir))
+	assert.Nil(t, asset.PreserveModTime(testDir))
+	info, err := os.Stat(filepath.Join(testDir, "blah.txt"))
+	if assert.Nil(t, err) {
+		assert.True(t, updatedAt.Equal(info.ModTime()))
+	}
+
+	assert.Nil(t, Asset{Key: "nope.txt"}.PreserveModTime(testDir))
+	assert.NotNil(t, Asset{Key: "nope.txt", UpdatedAt: asset.UpdatedAt}.PreserveModTime(testDir))
+}
+
 func TestAsset_Contents(t *testing.T) {
 	testcases := []struct {
 		asset  Asset
@@ -116,6 +161,89 @@ func TestLoadAssetsFromDirectory(t *testing.T) {
 	}
 }
 
+func TestLoadAssetsFromDirectory_Order(t *testing.T) {
+	e := &env.Env{Directory: filepath.Join("_testdata", "project")}
+	ignoreNone := func(path string) bool { return strings.Contains(path, ".gitkeep") }
+	for i := 0; i < 5; i++ {
+		assets, err := loadAssetsFromDirectory(e, "", ignoreNone)
+		assert.Nil(t, err)
+		keys := []string{}
+		for _, asset := range assets {
+			keys = append(keys, asset.Key)
+		}
+		assert.True(t, sort.StringsAreSorted(keys), "%v", keys)
+	}
+}
+
+func BenchmarkLoadAssetsFromDirectory(b *testing.B) {
+	dir, err := ioutil.TempDir("", "themekit-bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "snippets"), 0755)
+	for i := 0; i < 2000; i++ {
+		contents := []byte(fmt.Sprintf("<div>snippet %v</div>", i))
+		if err := ioutil.WriteFile(filepath.Join(dir, "snippets", fmt.Sprintf("%04d.liquid", i)), contents, 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	e := &env.Env{Directory: dir}
+	ignoreNone := func(string) bool { return false }
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := loadAssetsFromDirectory(e, "", ignoreNone); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestLoadAssetsFromDirectory_FollowSymlinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "themekit-symlinks")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	project, shared := filepath.Join(dir, "project"), filepath.Join(dir, "shared")
+	assert.Nil(t, os.MkdirAll(filepath.Join(project, "templates"), 0755))
+	assert.Nil(t, os.MkdirAll(shared, 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(project, "templates", "index.liquid"), []byte("index"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(shared, "header.liquid"), []byte("header"), 0644))
+	assert.Nil(t, os.Symlink(shared, filepath.Join(project, "snippets")))
+	assert.Nil(t, os.Symlink(project, filepath.Join(shared, "loop")))
+
+	ignoreNone := func(path string) bool { return false }
+	e := &env.Env{Directory: project}
+	assets, err := loadAssetsFromDirectory(e, "", ignoreNone)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"templates/index.liquid"}, assetsToFilenames(assets))
+
+	e.FollowSymlinks = true
+	assets, err = loadAssetsFromDirectory(e, "", ignoreNone)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"snippets/header.liquid", "templates/index.liquid"}, assetsToFilenames(assets))
+}
+
+func TestLoadAssetsFromDirectory_ReadErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "themekit-read-errors")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "templates"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "templates", "index.liquid"), []byte("index"), 0644))
+	assert.Nil(t, os.Symlink(filepath.Join(dir, "nope.liquid"), filepath.Join(dir, "templates", "broken.liquid")))
+
+	e := &env.Env{Directory: dir}
+	assets, err := loadAssetsFromDirectory(e, "", func(string) bool { return false })
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "could not read templates/broken.liquid")
+	}
+	assert.Equal(t, []string{"templates/index.liquid"}, assetsToFilenames(assets))
+
+	_, err = FindAssets(e, "templates")
+	assert.NotNil(t, err)
+}
+
 func TestReadAsset(t *testing.T) {
 	e := &env.Env{Directory: filepath.Join("_testdata", "project")}
 
@@ -126,6 +254,8 @@ func TestReadAsset(t *testing.T) {
 	}{
 		{input: filepath.Join("assets", "application.js"), expected: Asset{Key: "assets/application.js", Value: "this is js content", Checksum: "f980fcdcfeb5bcf24c0de5c199c3a94b"}},
 		{input: filepath.Join(".", "assets", "application.js"), expected: Asset{Key: "assets/application.js", Value: "this is js content", Checksum: "f980fcdcfeb5bcf24c0de5c199c3a94b"}},
+		{input: "/assets/application.js", expected: Asset{Key: "assets/application.js", Value: "this is js content", Checksum: "f980fcdcfeb5bcf24c0de5c199c3a94b"}},
+		{input: `assets\application.js`, expected: Asset{Key: "assets/application.js", Value: "this is js content", Checksum: "f980fcdcfeb5bcf24c0de5c199c3a94b"}},
 		{input: "nope.txt", expected: Asset{}, err: " "},
 		{input: "assets", expected: Asset{}, err: ErrAssetIsDir.Error()},
 		{input: filepath.Join("assets", "image.png"), expected: Asset{Key: "assets/image.png", Attachment: "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAAEUlEQVR4nGJiYGBgAAQAAP//AA8AA/6P688AAAAASUVORK5CYII=", Checksum: "9e24e19b024c44b778301d880bd8e6f4"}},
@@ -147,3 +277,72 @@ func TestReadAsset(t *testing.T) {
 		}
 	}
 }
+
+func TestNormalizeKey(t *testing.T) {
+	testcases := []struct {
+		input, expected string
+	}{
+		{input: "templates/index.liquid", expected: "templates/index.liquid"},
+		{input: "/templates/index.liquid", expected: "templates/index.liquid"},
+		{input: `templates\index.liquid`, expected: "templates/index.liquid"},
+		{input: `\templates\customers/login.liquid`, expected: "templates/customers/login.liquid"},
+		{input: "./templates//index.liquid", expected: "templates/index.liquid"},
+		{input: "templates/", expected: "templates"},
+		{input: "templates/**/*.liquid", expected: "templates/**/*.liquid"},
+		{input: "/", expected: ""},
+		{input: "", expected: ""},
+	}
+
+	for _, testcase := range testcases {
+		assert.Equal(t, testcase.expected, NormalizeKey(testcase.input), testcase.input)
+	}
+}
+
+func TestIsBinaryAsset(t *testing.T) {
+	png, err := ioutil.ReadFile(filepath.Join("_testdata", "project", "assets", "image.png"))
+	assert.Nil(t, err)
+
+	testcases := []struct {
+		key, contentType string
+		contents         []byte
+		binary           bool
+	}{
+		{key: "assets/image.png", contents: png, binary: true},
+		{key: "assets/image.dat", contents: png, binary: true},
+		{key: "assets/font.woff2", contents: []byte("wOF2 looks like text"), binary: true},
+		{key: "templates/index.liquid", contents: []byte("{{ content_for_layout }}"), binary: false},
+		{key: "templates/empty.liquid", contents: []byte{}, binary: false},
+		{key: "assets/app.js", contents: []byte{0xff, 0xfe, 0x00, 0x01}, binary: true},
+		{key: "assets/readme.unknown", contents: []byte("plain text"), binary: false},
+		{key: "assets/model.gltf", contentType: "model/gltf+json", contents: png, binary: false},
+		{key: "assets/data.custom", contentType: "application/octet-stream", contents: []byte("plain text"), binary: true},
+	}
+
+	for _, testcase := range testcases {
+		assert.Equal(t, testcase.binary, isBinaryAsset(testcase.key, testcase.contentType, testcase.contents), testcase.key)
+	}
+}
+
+func TestContentTypeFor(t *testing.T) {
+	contentTypes := map[string]string{".gltf": "model/gltf+json", "USDZ": "model/vnd.usdz+zip"}
+	assert.Equal(t, "model/gltf+json", contentTypeFor("assets/model.gltf", contentTypes))
+	assert.Equal(t, "model/vnd.usdz+zip", contentTypeFor("assets/model.usdz", contentTypes))
+	assert.Equal(t, "", contentTypeFor("assets/app.js", contentTypes))
+	assert.Equal(t, "", contentTypeFor("assets/app.js", nil))
+}
+
+func TestAsset_Ignored(t *testing.T) {
+	testcases := []struct {
+		asset   Asset
+		ignored bool
+	}{
+		{asset: Asset{Key: "templates/index.liquid", Value: "{% comment %}themekit:ignore{% endcomment %}\n<h1>hi</h1>"}, ignored: true},
+		{asset: Asset{Key: "templates/index.liquid", Value: "<h1>hi</h1>"}, ignored: false},
+		{asset: Asset{Key: "templates/index.liquid", Value: strings.Repeat(" ", ignoreDirectiveScanSize) + "themekit:ignore"}, ignored: false},
+		{asset: Asset{Key: "assets/image.png", Attachment: "dGhlbWVraXQ6aWdub3Jl"}, ignored: false},
+	}
+
+	for _, testcase := range testcases {
+		assert.Equal(t, testcase.ignored, testcase.asset.Ignored(), testcase.asset.Value)
+	}
+}
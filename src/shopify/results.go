@@ -0,0 +1,37 @@
+package shopify
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Shopify/themekit/src/file"
+)
+
+// AssetEvent is the outcome of a single asset request. Response is the response
+// from shopify, it is nil if the request could not be made and its body has
+// already been read.
+type AssetEvent struct {
+	Event    file.Op
+	Asset    Asset
+	Err      error
+	Response *http.Response
+}
+
+// ResultHandler receives the outcome of asset requests. Requests may be made from
+// many goroutines at once so a handler must be safe for concurrent use.
+type ResultHandler func(AssetEvent)
+
+type resultHandlerKey struct{}
+
+// WithResults returns a copy of ctx that reports the outcome of every GetAsset,
+// UpdateAsset and DeleteAsset call made with it to handler, so that programs
+// using the client can present the results their own way.
+func WithResults(ctx context.Context, handler ResultHandler) context.Context {
+	return context.WithValue(ctx, resultHandlerKey{}, handler)
+}
+
+func reportResult(ctx context.Context, event AssetEvent) {
+	if handler, ok := ctx.Value(resultHandlerKey{}).(ResultHandler); ok && handler != nil {
+		handler(event)
+	}
+}
@@ -0,0 +1,46 @@
+package shopify
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Shopify/themekit/src/env"
+	"github.com/Shopify/themekit/src/file"
+	"github.com/Shopify/themekit/src/shopify/_mocks"
+)
+
+func TestWithResults(t *testing.T) {
+	m := new(mocks.HttpAdapter)
+	client, _ := NewClient(&env.Env{ThemeID: "123"})
+	client.http = m
+
+	events := []AssetEvent{}
+	ctx := WithResults(context.Background(), func(event AssetEvent) {
+		events = append(events, event)
+	})
+
+	updated := jsonResponse(`{"asset":{"key":"assets/app.js"}}`, 200)
+	m.On("Put", mock.Anything, APIPath+"themes/123/assets.json", map[string]Asset{"asset": {Key: "assets/app.js"}}, map[string]string{}).Return(updated, nil)
+	m.On("Get", mock.Anything, APIPath+"themes/123/assets.json?asset%5Bkey%5D=assets%2Flogo.png", NoHeaders).Return(nil, errors.New("server error"))
+	m.On("Delete", mock.Anything, APIPath+"themes/123/assets.json?asset%5Bkey%5D=assets%2Fold.js", NoHeaders).Return(jsonResponse(`{}`, 404), nil)
+
+	assert.Nil(t, client.UpdateAsset(ctx, Asset{Key: "assets/app.js"}, ""))
+	_, err := client.GetAsset(ctx, "assets/logo.png")
+	assert.NotNil(t, err)
+	assert.Equal(t, ErrNotPartOfTheme, client.DeleteAsset(ctx, Asset{Key: "assets/old.js"}))
+
+	if assert.Equal(t, 3, len(events)) {
+		assert.Equal(t, AssetEvent{Event: file.Update, Asset: Asset{Key: "assets/app.js"}, Response: updated}, events[0])
+		assert.Equal(t, AssetEvent{Event: file.Get, Asset: Asset{Key: "assets/logo.png"}, Err: errors.New("server error")}, events[1])
+		assert.Equal(t, file.Remove, events[2].Event)
+		assert.Equal(t, ErrNotPartOfTheme, events[2].Err)
+		assert.Equal(t, 404, events[2].Response.StatusCode)
+	}
+
+	assert.Equal(t, ErrNotPartOfTheme, client.DeleteAsset(context.Background(), Asset{Key: "assets/old.js"}))
+	assert.Equal(t, 3, len(events))
+}
@@ -1,6 +1,7 @@
 package shopify
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -13,8 +14,13 @@ import (
 	"github.com/Shopify/themekit/src/httpify"
 )
 
-// APIPath is the version of the Admin REST API to use
-const APIPath = "/admin/api/unstable/"
+const (
+	// DefaultAPIVersion is the version of the Admin REST API used unless the
+	// environment sets an api_version
+	DefaultAPIVersion = "2023-10"
+	// APIPath is the path of the default version of the Admin REST API
+	APIPath = "/admin/api/" + DefaultAPIVersion + "/"
+)
 
 var (
 	// ErrCriticalFile will be returned when trying to remove a critical file
@@ -44,6 +50,7 @@ type Theme struct {
 	Role        string `json:"role,omitempty"`
 	Previewable bool   `json:"previewable,omitempty"`
 	Processing  bool   `json:"processing,omitempty"`
+	UpdatedAt   string `json:"updated_at,omitempty"`
 }
 
 // Shop information for the domain your are currently working on
@@ -74,49 +81,69 @@ type assetsResponse struct {
 }
 
 type httpAdapter interface {
-	Get(string, map[string]string) (*http.Response, error)
-	Post(string, interface{}, map[string]string) (*http.Response, error)
-	Put(string, interface{}, map[string]string) (*http.Response, error)
-	Delete(string, map[string]string) (*http.Response, error)
+	Get(context.Context, string, map[string]string) (*http.Response, error)
+	Post(context.Context, string, interface{}, map[string]string) (*http.Response, error)
+	Put(context.Context, string, interface{}, map[string]string) (*http.Response, error)
+	Delete(context.Context, string, map[string]string) (*http.Response, error)
 }
 
 // Client is the interactor with the shopify server. All actions are processed
 // with the client.
 type Client struct {
-	themeID string
-	filter  file.Filter
-	http    httpAdapter
+	themeID    string
+	apiVersion string
+	filter     file.Filter
+	http       httpAdapter
 }
 
 // NewClient will build a new theme client from a configuration and a theme event
 // channel. The channel is used for logging all events. The configuration specifies how
 // the client will behave.
 func NewClient(e *env.Env) (Client, error) {
-	filter, err := file.NewFilter(e.Directory, e.IgnoredFiles, e.Ignores)
+	filter, err := file.NewFilter(e.Directory, e.IgnoredFiles, e.Ignores, e.IncludedFiles)
 	if err != nil {
 		return Client{}, err
 	}
 
+	timeout := e.Timeout
+	if timeout == 0 && e.IsSet("timeout") {
+		timeout = httpify.NoTimeout
+	}
+	maxRetries := e.MaxRetries
+	if maxRetries == 0 && e.IsSet("max_retries") {
+		maxRetries = httpify.NoRetries
+	}
+	callLimit := e.APICallLimit
+	if callLimit == 0 && e.IsSet("api_call_limit") {
+		callLimit = httpify.NoCallLimit
+	}
+
 	http, err := httpify.NewClient(httpify.Params{
-		Domain:   e.Domain,
-		Password: e.Password,
-		Proxy:    e.Proxy,
-		Timeout:  e.Timeout,
+		Domain:         e.Domain,
+		Password:       e.Password,
+		AccessToken:    e.AccessToken,
+		APIKey:         e.APIKey,
+		Proxy:          e.Proxy,
+		Timeout:        timeout,
+		ConnectTimeout: e.ConnectTimeout,
+		MaxRetries:     maxRetries,
+		APICallLimit:   callLimit,
 	})
 	if err != nil {
 		return Client{}, err
 	}
 
 	return Client{
-		themeID: e.ThemeID,
-		http:    http,
-		filter:  filter,
+		themeID:    e.ThemeID,
+		apiVersion: e.APIVersion,
+		http:       http,
+		filter:     filter,
 	}, nil
 }
 
 // GetShop will return information for the shop you are working on
 func (c Client) GetShop() (Shop, error) {
-	resp, err := c.http.Get("/meta.json", nil)
+	resp, err := c.http.Get(context.Background(), "/meta.json", nil)
 	if err != nil {
 		return Shop{}, err
 	} else if resp.StatusCode == 404 {
@@ -133,7 +160,7 @@ func (c Client) GetShop() (Shop, error) {
 
 // Themes will return all the available themes on a domain.
 func (c Client) Themes() ([]Theme, error) {
-	resp, err := c.http.Get(APIPath+"themes.json", nil)
+	resp, err := c.http.Get(context.Background(), c.apiPath()+"themes.json", nil)
 	if err != nil {
 		return []Theme{}, err
 	}
@@ -153,7 +180,7 @@ func (c *Client) CreateNewTheme(name string) (theme Theme, err error) {
 		return Theme{}, ErrThemeNameRequired
 	}
 
-	resp, err := c.http.Post(APIPath+"themes.json", map[string]interface{}{"theme": Theme{Name: name}}, nil)
+	resp, err := c.http.Post(context.Background(), c.apiPath()+"themes.json", map[string]interface{}{"theme": Theme{Name: name}}, nil)
 	if err != nil {
 		return Theme{}, err
 	}
@@ -177,7 +204,7 @@ func (c Client) GetInfo() (Theme, error) {
 		return Theme{}, ErrInfoWithoutThemeID
 	}
 
-	resp, err := c.http.Get(fmt.Sprintf(APIPath+"themes/%s.json", c.themeID), nil)
+	resp, err := c.http.Get(context.Background(), fmt.Sprintf(c.apiPath()+"themes/%s.json", c.themeID), nil)
 	if err != nil {
 		return Theme{}, err
 	} else if resp.StatusCode == 404 {
@@ -199,7 +226,8 @@ func (c Client) PublishTheme() error {
 	}
 
 	resp, err := c.http.Put(
-		fmt.Sprintf(APIPath+"themes/%s.json", c.themeID),
+		context.Background(),
+		fmt.Sprintf(c.apiPath()+"themes/%s.json", c.themeID),
 		map[string]Theme{"theme": {Role: "main"}},
 		nil,
 	)
@@ -226,22 +254,31 @@ func (c Client) PublishTheme() error {
 // The assets returned will not have any data, only ID and filenames. This is because
 // fetching all the assets at one time is not a good idea.
 func (c Client) GetAllAssets() ([]Asset, error) {
-	resp, err := c.http.Get(c.assetPath(map[string]string{"fields": "key,checksum"}), nil)
-	if err != nil {
-		return []Asset{}, err
-	} else if resp.StatusCode == 404 {
-		return []Asset{}, ErrThemeNotFound
-	}
+	allAssets := []Asset{}
+	path := c.assetPath(map[string]string{"fields": "key,checksum,updated_at"})
+	// a link back to a page that was already fetched would otherwise never end
+	visited := map[string]bool{}
+	for path != "" && !visited[path] {
+		visited[path] = true
+		resp, err := c.http.Get(context.Background(), path, nil)
+		if err != nil {
+			return []Asset{}, err
+		} else if resp.StatusCode == 404 {
+			return []Asset{}, ErrThemeNotFound
+		}
 
-	var r assetsResponse
-	if err := unmarshalResponse(resp, &r); err != nil {
-		return []Asset{}, err
+		var r assetsResponse
+		if err := unmarshalResponse(resp, &r); err != nil {
+			return []Asset{}, err
+		}
+		allAssets = append(allAssets, r.Assets...)
+		path = nextPagePath(resp)
 	}
 
 	filteredAssets := []Asset{}
-	sort.Slice(r.Assets, func(i, j int) bool { return r.Assets[i].Key < r.Assets[j].Key })
-	for index, asset := range r.Assets {
-		if !c.filter.Match(asset.Key) && (index == len(r.Assets)-1 || r.Assets[index+1].Key != asset.Key+".liquid") {
+	sort.Slice(allAssets, func(i, j int) bool { return allAssets[i].Key < allAssets[j].Key })
+	for index, asset := range allAssets {
+		if !c.filter.Match(asset.Key) && (index == len(allAssets)-1 || allAssets[index+1].Key != asset.Key+".liquid") {
 			filteredAssets = append(filteredAssets, asset)
 		}
 	}
@@ -250,95 +287,142 @@ func (c Client) GetAllAssets() ([]Asset, error) {
 }
 
 // GetAsset will fetch a single remote asset from the remote shopify servers.
-func (c Client) GetAsset(filename string) (Asset, error) {
-	resp, err := c.http.Get(c.assetPath(map[string]string{"asset[key]": filename}), nil)
+func (c Client) GetAsset(ctx context.Context, filename string) (Asset, error) {
+	asset, resp, err := c.getAsset(ctx, filename)
 	if err != nil {
-		return Asset{}, err
+		reportResult(ctx, AssetEvent{Event: file.Get, Asset: Asset{Key: filename}, Err: err, Response: resp})
+	} else {
+		reportResult(ctx, AssetEvent{Event: file.Get, Asset: asset, Response: resp})
+	}
+	return asset, err
+}
+
+func (c Client) getAsset(ctx context.Context, filename string) (Asset, *http.Response, error) {
+	resp, err := c.http.Get(ctx, c.assetPath(map[string]string{"asset[key]": filename}), nil)
+	if err != nil {
+		return Asset{}, nil, err
 	} else if resp.StatusCode == 404 {
-		return Asset{}, ErrNotPartOfTheme
+		return Asset{}, resp, ErrNotPartOfTheme
 	}
 
 	var r assetResponse
 	if err := unmarshalResponse(resp, &r); err != nil {
-		return Asset{}, err
+		return Asset{}, resp, err
 	}
 
-	return r.Asset, nil
+	return r.Asset, resp, nil
 }
 
 // CreateAsset will take an asset and will return when the asset has been created.
 // If there was an error, in the request then error will be defined otherwise the
 // response will have the appropriate data for usage.
-func (c Client) CreateAsset(asset Asset) error {
-	return c.UpdateAsset(asset, "")
+func (c Client) CreateAsset(ctx context.Context, asset Asset) error {
+	return c.UpdateAsset(ctx, asset, "")
 }
 
 // UpdateAsset will take an asset and will return when the asset has been updated.
 // If there was an error, in the request then error will be defined otherwise the
 // response will have the appropriate data for usage.
-func (c Client) UpdateAsset(asset Asset, lastKnownChecksum string) error {
+func (c Client) UpdateAsset(ctx context.Context, asset Asset, lastKnownChecksum string) error {
+	resp, err := c.updateAsset(ctx, asset, lastKnownChecksum)
+	reportResult(ctx, AssetEvent{Event: file.Update, Asset: asset, Err: err, Response: resp})
+	return err
+}
+
+func (c Client) updateAsset(ctx context.Context, asset Asset, lastKnownChecksum string) (*http.Response, error) {
 	var header = make(map[string]string)
 	if lastKnownChecksum != "" {
 		header["X-Shopify-Replace-If-Checksum-Match"] = lastKnownChecksum
 	}
-	resp, err := c.http.Put(c.assetPath(map[string]string{}), map[string]Asset{"asset": asset}, header)
+	resp, err := c.http.Put(ctx, c.assetPath(map[string]string{}), map[string]Asset{"asset": asset}, header)
 	if err != nil {
-		return err
+		return nil, err
 	} else if resp.StatusCode == 404 {
-		return ErrNotPartOfTheme
+		return resp, ErrNotPartOfTheme
 	}
 
 	var r assetResponse
 	if err := unmarshalResponse(resp, &r); err != nil {
-		return err
+		return resp, err
 	}
 
 	if len(r.Errors) > 0 {
 		if _, ok := r.Errors["asset"]; ok {
 			if resp.StatusCode == 422 && strings.Contains(r.Errors["asset"][0], "Cannot overwrite generated asset") {
 				// No need to check the error because if it fails then remove will be tried again.
-				c.DeleteAsset(Asset{Key: asset.Key + ".liquid"})
-				return c.UpdateAsset(asset, lastKnownChecksum)
+				c.deleteAsset(ctx, Asset{Key: asset.Key + ".liquid"})
+				return c.updateAsset(ctx, asset, lastKnownChecksum)
 			}
-			return errors.New(toSentence(r.Errors["asset"]))
+			return resp, errors.New(toSentence(r.Errors["asset"]))
 		}
-		return errors.New(toSentence(toMessages(r.Errors)))
+		return resp, errors.New(toSentence(toMessages(r.Errors)))
 	}
 
-	return nil
+	return resp, nil
 }
 
 // DeleteAsset will take an asset and will return when the asset has been deleted.
 // If there was an error, in the request then error will be defined otherwise the
-//response will have the appropropriate data for usage.
-func (c Client) DeleteAsset(asset Asset) error {
-	resp, err := c.http.Delete(c.assetPath(map[string]string{"asset[key]": asset.Key}), nil)
+// response will have the appropropriate data for usage.
+func (c Client) DeleteAsset(ctx context.Context, asset Asset) error {
+	resp, err := c.deleteAsset(ctx, asset)
+	reportResult(ctx, AssetEvent{Event: file.Remove, Asset: asset, Err: err, Response: resp})
+	return err
+}
+
+func (c Client) deleteAsset(ctx context.Context, asset Asset) (*http.Response, error) {
+	resp, err := c.http.Delete(ctx, c.assetPath(map[string]string{"asset[key]": asset.Key}), nil)
 	if err != nil {
-		return err
+		return nil, err
 	} else if resp.StatusCode == 403 {
-		return ErrCriticalFile
+		return resp, ErrCriticalFile
 	} else if resp.StatusCode == 404 {
-		return ErrNotPartOfTheme
+		return resp, ErrNotPartOfTheme
 	} else if resp.StatusCode == 406 {
-		return ErrMissingAssetName
+		return resp, ErrMissingAssetName
 	}
 
 	var r assetResponse
 	if err := unmarshalResponse(resp, &r); err != nil {
-		return err
+		return resp, err
 	}
 
 	if len(r.Errors) > 0 {
-		return errors.New(toSentence(toMessages(r.Errors)))
+		return resp, errors.New(toSentence(toMessages(r.Errors)))
 	}
 
-	return nil
+	return resp, nil
+}
+
+// nextPagePath will return the request path of the next page of results from the
+// Link header of a paginated response, or an empty string on the last page.
+func nextPagePath(resp *http.Response) string {
+	for _, link := range strings.Split(resp.Header.Get("Link"), ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 || !strings.Contains(strings.Join(parts[1:], ";"), `rel="next"`) {
+			continue
+		}
+		next, err := url.Parse(strings.Trim(strings.TrimSpace(parts[0]), "<>"))
+		if err != nil {
+			return ""
+		}
+		return next.RequestURI()
+	}
+	return ""
+}
+
+// apiPath returns the base path of the configured version of the Admin REST API
+func (c Client) apiPath() string {
+	if c.apiVersion == "" {
+		return APIPath
+	}
+	return "/admin/api/" + c.apiVersion + "/"
 }
 
 func (c Client) assetPath(query map[string]string) string {
-	formatted := APIPath + "assets.json"
+	formatted := c.apiPath() + "assets.json"
 	if c.themeID != "" {
-		formatted = fmt.Sprintf(APIPath+"themes/%s/assets.json", c.themeID)
+		formatted = fmt.Sprintf(c.apiPath()+"themes/%s/assets.json", c.themeID)
 	}
 
 	if len(query) > 0 {
@@ -1,6 +1,7 @@
 package shopify
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -57,7 +58,7 @@ func TestThemeClient_GetShop(t *testing.T) {
 		client, _ := NewClient(&env.Env{ThemeID: testcase.themeID})
 		client.http = m
 
-		expectation := m.On("Get", "/meta.json", NoHeaders)
+		expectation := m.On("Get", mock.Anything, "/meta.json", NoHeaders)
 		if testcase.resperr != "" {
 			expectation.Return(nil, errors.New(testcase.resperr))
 		} else {
@@ -94,7 +95,7 @@ func TestThemeClient_Themes(t *testing.T) {
 		client, _ := NewClient(&env.Env{})
 		client.http = m
 
-		expectation := m.On("Get", APIPath+"themes.json", NoHeaders)
+		expectation := m.On("Get", mock.Anything, APIPath+"themes.json", NoHeaders)
 		if testcase.resperr != "" {
 			expectation.Return(nil, errors.New(testcase.resperr))
 		} else {
@@ -135,9 +136,9 @@ func TestThemeClient_CreateNewTheme(t *testing.T) {
 		query := map[string]interface{}{"theme": Theme{Name: testcase.in}}
 
 		if testcase.resp != "" {
-			m.On("Post", APIPath+"themes.json", query, NoHeaders).Return(jsonResponse(testcase.resp, 200), nil)
+			m.On("Post", mock.Anything, APIPath+"themes.json", query, NoHeaders).Return(jsonResponse(testcase.resp, 200), nil)
 		} else if testcase.resperr != "" {
-			m.On("Post", APIPath+"themes.json", query, NoHeaders).Return(nil, errors.New(testcase.resperr))
+			m.On("Post", mock.Anything, APIPath+"themes.json", query, NoHeaders).Return(nil, errors.New(testcase.resperr))
 		}
 
 		theme, err := client.CreateNewTheme(testcase.in)
@@ -173,7 +174,7 @@ func TestThemeClient_GetInfo(t *testing.T) {
 		client, _ := NewClient(&env.Env{ThemeID: testcase.themeID})
 		client.http = m
 
-		expectation := m.On("Get", fmt.Sprintf(APIPath+"themes/%s.json", testcase.themeID), NoHeaders)
+		expectation := m.On("Get", mock.Anything, fmt.Sprintf(APIPath+"themes/%s.json", testcase.themeID), NoHeaders)
 		if testcase.resperr != "" {
 			expectation.Return(nil, errors.New(testcase.resperr))
 		} else {
@@ -216,6 +217,7 @@ func TestThemeClient_PublishTheme(t *testing.T) {
 
 		expectation := m.On(
 			"Put",
+			mock.Anything,
 			fmt.Sprintf(APIPath+"themes/%s.json", testcase.themeID),
 			map[string]Theme{"theme": {Role: "main"}},
 			NoHeaders,
@@ -256,7 +258,7 @@ func TestThemeClient_GetAllAssets(t *testing.T) {
 		client, _ := NewClient(&env.Env{ThemeID: "123"})
 		client.http = m
 
-		expectation := m.On("Get", APIPath+"themes/123/assets.json?fields=key%2Cchecksum", NoHeaders)
+		expectation := m.On("Get", mock.Anything, APIPath+"themes/123/assets.json?fields=key%2Cchecksum%2Cupdated_at", NoHeaders)
 		if testcase.resperr != "" {
 			expectation.Return(nil, errors.New(testcase.resperr))
 		} else {
@@ -275,6 +277,31 @@ func TestThemeClient_GetAllAssets(t *testing.T) {
 		m.AssertExpectations(t)
 	}
 
+	m := new(mocks.HttpAdapter)
+	client, _ := NewClient(&env.Env{ThemeID: "123"})
+	client.http = m
+	firstPage := jsonResponse(`{"assets":[{"key":"assets/b.txt"}]}`, 200)
+	firstPage.Header = http.Header{"Link": []string{`<https://shop.myshopify.com/admin/api/2023-10/themes/123/assets.json?page_info=abc>; rel="next"`}}
+	m.On("Get", mock.Anything, APIPath+"themes/123/assets.json?fields=key%2Cchecksum%2Cupdated_at", NoHeaders).Return(firstPage, nil)
+	m.On("Get", mock.Anything, APIPath+"themes/123/assets.json?page_info=abc", NoHeaders).Return(jsonResponse(`{"assets":[{"key":"assets/a.txt"}]}`, 200), nil)
+	assets, err := client.GetAllAssets()
+	assert.Nil(t, err)
+	assert.Equal(t, []Asset{{Key: "assets/a.txt"}, {Key: "assets/b.txt"}}, assets)
+	m.AssertExpectations(t)
+
+	m = new(mocks.HttpAdapter)
+	client.http = m
+	firstPage = jsonResponse(`{"assets":[{"key":"assets/b.txt"}]}`, 200)
+	firstPage.Header = http.Header{"Link": []string{`<https://shop.myshopify.com/admin/api/2023-10/themes/123/assets.json?page_info=abc>; rel="next"`}}
+	secondPage := jsonResponse(`{"assets":[{"key":"assets/a.txt"}]}`, 200)
+	secondPage.Header = http.Header{"Link": []string{`<https://shop.myshopify.com/admin/api/2023-10/themes/123/assets.json?page_info=abc>; rel="next"`}}
+	m.On("Get", mock.Anything, APIPath+"themes/123/assets.json?fields=key%2Cchecksum%2Cupdated_at", NoHeaders).Return(firstPage, nil).Once()
+	m.On("Get", mock.Anything, APIPath+"themes/123/assets.json?page_info=abc", NoHeaders).Return(secondPage, nil).Once()
+	assets, err = client.GetAllAssets()
+	assert.Nil(t, err)
+	assert.Equal(t, []Asset{{Key: "assets/a.txt"}, {Key: "assets/b.txt"}}, assets)
+	m.AssertExpectations(t)
+
 	filtertestcases := []struct {
 		input    string
 		ignore   []string
@@ -299,7 +326,7 @@ func TestThemeClient_GetAllAssets(t *testing.T) {
 		m := new(mocks.HttpAdapter)
 		client, _ := NewClient(&env.Env{ThemeID: "123", IgnoredFiles: testcase.ignore})
 		client.http = m
-		m.On("Get", APIPath+"themes/123/assets.json?fields=key%2Cchecksum", NoHeaders).Return(jsonResponse(testcase.input, 200), nil)
+		m.On("Get", mock.Anything, APIPath+"themes/123/assets.json?fields=key%2Cchecksum%2Cupdated_at", NoHeaders).Return(jsonResponse(testcase.input, 200), nil)
 		assets, err := client.GetAllAssets()
 		assert.Nil(t, err)
 		assert.Equal(t, testcase.expected, assets)
@@ -322,14 +349,14 @@ func TestThemeClient_GetAsset(t *testing.T) {
 		client, _ := NewClient(&env.Env{ThemeID: "123"})
 		client.http = m
 
-		expectation := m.On("Get", APIPath+"themes/123/assets.json?asset%5Bkey%5D=filename.txt", NoHeaders)
+		expectation := m.On("Get", mock.Anything, APIPath+"themes/123/assets.json?asset%5Bkey%5D=filename.txt", NoHeaders)
 		if testcase.resperr != "" {
 			expectation.Return(nil, errors.New(testcase.resperr))
 		} else if testcase.code != 0 {
 			expectation.Return(jsonResponse(testcase.resp, testcase.code), nil)
 		}
 
-		asset, err := client.GetAsset("filename.txt")
+		asset, err := client.GetAsset(context.Background(), "filename.txt")
 
 		if testcase.err == "" {
 			assert.Nil(t, err)
@@ -358,14 +385,14 @@ func TestThemeClient_UpdateAsset(t *testing.T) {
 		client, _ := NewClient(&env.Env{ThemeID: "123"})
 		client.http = m
 
-		expectation := m.On("Put", APIPath+"themes/123/assets.json", map[string]Asset{"asset": {Key: "filename.txt"}}, map[string]string{})
+		expectation := m.On("Put", mock.Anything, APIPath+"themes/123/assets.json", map[string]Asset{"asset": {Key: "filename.txt"}}, map[string]string{})
 		if testcase.resperr != "" {
 			expectation.Return(nil, errors.New(testcase.resperr))
 		} else if testcase.code != 0 {
 			expectation.Return(jsonResponse(testcase.resp, testcase.code), nil)
 		}
 
-		err := client.UpdateAsset(Asset{Key: "filename.txt"}, "")
+		err := client.UpdateAsset(context.Background(), Asset{Key: "filename.txt"}, "")
 
 		if testcase.err == "" {
 			assert.Nil(t, err)
@@ -384,6 +411,7 @@ func TestThemeClient_UpdateAsset(t *testing.T) {
 	count := 0
 	m.On(
 		"Put",
+		mock.Anything,
 		mock.MatchedBy(func(path string) bool {
 			if count == 0 {
 				count++
@@ -400,18 +428,20 @@ func TestThemeClient_UpdateAsset(t *testing.T) {
 
 	m.On(
 		"Delete",
+		mock.Anything,
 		APIPath+"themes/123/assets.json?asset%5Bkey%5D=filename.txt.liquid",
 		NoHeaders,
 	).Return(jsonResponse("{}", 200), nil)
 
 	m.On(
 		"Put",
+		mock.Anything,
 		APIPath+"themes/123/assets.json",
 		map[string]Asset{"asset": asset},
 		map[string]string{},
 	).Return(jsonResponse(`{"asset":{"key":"assets/hello.txt"}}`, 200), nil)
 
-	assert.Nil(t, client.UpdateAsset(asset, ""))
+	assert.Nil(t, client.UpdateAsset(context.Background(), asset, ""))
 	m.AssertExpectations(t)
 }
 
@@ -431,14 +461,14 @@ func TestThemeClient_DeleteAsset(t *testing.T) {
 		client, _ := NewClient(&env.Env{ThemeID: "123"})
 		client.http = m
 
-		expectation := m.On("Delete", APIPath+"themes/123/assets.json?asset%5Bkey%5D=filename.txt", NoHeaders)
+		expectation := m.On("Delete", mock.Anything, APIPath+"themes/123/assets.json?asset%5Bkey%5D=filename.txt", NoHeaders)
 		if testcase.resperr != "" {
 			expectation.Return(nil, errors.New(testcase.resperr))
 		} else {
 			expectation.Return(jsonResponse(testcase.resp, testcase.code), nil)
 		}
 
-		err := client.DeleteAsset(Asset{Key: "filename.txt"})
+		err := client.DeleteAsset(context.Background(), Asset{Key: "filename.txt"})
 
 		if testcase.err == "" {
 			assert.Nil(t, err)
@@ -452,17 +482,18 @@ func TestThemeClient_DeleteAsset(t *testing.T) {
 
 func TestThemeClient_assetPath(t *testing.T) {
 	testcases := []struct {
-		query         map[string]string
-		themeID, path string
+		query                     map[string]string
+		themeID, apiVersion, path string
 	}{
 		{themeID: "123", path: APIPath + "themes/123/assets.json?asset%5Bkey%5D=layout%2Ftheme.liquid", query: map[string]string{"asset[key]": "layout/theme.liquid"}},
-		{path: "/admin/api/unstable/assets.json?asset%5Bkey%5D=layout%2Ftheme.liquid", query: map[string]string{"asset[key]": "layout/theme.liquid"}},
+		{path: "/admin/api/2023-10/assets.json?asset%5Bkey%5D=layout%2Ftheme.liquid", query: map[string]string{"asset[key]": "layout/theme.liquid"}},
 		{themeID: "123", path: APIPath + "themes/123/assets.json"},
-		{path: "/admin/api/unstable/assets.json"},
+		{path: "/admin/api/2023-10/assets.json"},
+		{themeID: "123", apiVersion: "2024-01", path: "/admin/api/2024-01/themes/123/assets.json"},
 	}
 
 	for _, testcase := range testcases {
-		client, _ := NewClient(&env.Env{ThemeID: testcase.themeID})
+		client, _ := NewClient(&env.Env{ThemeID: testcase.themeID, APIVersion: testcase.apiVersion})
 		path := client.assetPath(testcase.query)
 		assert.Equal(t, testcase.path, path)
 	}
@@ -509,6 +540,24 @@ func (s *StringReadCloser) Close() error {
 	return nil
 }
 
+func TestNextPagePath(t *testing.T) {
+	testcases := []struct {
+		link, expected string
+	}{
+		{link: "", expected: ""},
+		{link: `<https://shop.myshopify.com/admin/api/2023-10/themes/1/assets.json?page_info=prev>; rel="previous"`, expected: ""},
+		{
+			link:     `<https://shop.myshopify.com/admin/api/2023-10/themes/1/assets.json?page_info=prev>; rel="previous", <https://shop.myshopify.com/admin/api/2023-10/themes/1/assets.json?page_info=next>; rel="next"`,
+			expected: APIPath + "themes/1/assets.json?page_info=next",
+		},
+	}
+
+	for _, testcase := range testcases {
+		resp := &http.Response{Header: http.Header{"Link": []string{testcase.link}}}
+		assert.Equal(t, testcase.expected, nextPagePath(resp))
+	}
+}
+
 func jsonResponse(body string, code int) *http.Response {
 	return &http.Response{
 		Body:       &StringReadCloser{strings.NewReader(body)},
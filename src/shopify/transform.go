@@ -0,0 +1,85 @@
+package shopify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ryanuber/go-glob"
+
+	"github.com/Shopify/themekit/src/env"
+)
+
+// TransformAsset will pipe the contents of the asset through the transform command
+// configured for the most specific glob that matches its key. Assets that do not
+// match any glob are returned unchanged. The command is split on whitespace and run
+// without a shell so arguments cannot be quoted, a command that needs quoting or
+// pipes should be put in a script.
+func TransformAsset(e *env.Env, asset Asset) (Asset, error) {
+	command := transformFor(asset.Key, e.Transforms)
+	if command == "" {
+		return asset, nil
+	}
+
+	args := strings.Fields(command)
+	contents := []byte(asset.Value)
+	if asset.Attachment != "" {
+		var err error
+		if contents, err = base64.StdEncoding.DecodeString(asset.Attachment); err != nil {
+			return asset, err
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = e.Directory
+	cmd.Stdin = bytes.NewReader(contents)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return asset, fmt.Errorf("transform '%s' failed for %s: %s", command, asset.Key, msg)
+	}
+
+	if asset.Attachment != "" {
+		asset.Attachment = base64.StdEncoding.EncodeToString(stdout.Bytes())
+		asset.Checksum = calculateByteArrayChecksum(stdout.Bytes())
+	} else {
+		asset.Value = stdout.String()
+		asset.Checksum = calculateTextChecksum(asset.Value, filepath.Ext(asset.Key) == ".json")
+	}
+	return asset, nil
+}
+
+// transformFor returns the command configured for the key, globs are matched
+// against the full key and the file name so that *.scss matches in any folder.
+// When more than one glob matches the longest one is the most specific so it is
+// used, like snippets/*.liquid over *.liquid.
+func transformFor(key string, transforms map[string]string) string {
+	patterns := []string{}
+	for pattern := range transforms {
+		patterns = append(patterns, pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+
+	for _, pattern := range patterns {
+		if strings.TrimSpace(transforms[pattern]) == "" {
+			continue
+		} else if glob.Glob(pattern, key) || glob.Glob(pattern, filepath.Base(key)) {
+			return transforms[pattern]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package shopify
+
+import (
+	"encoding/base64"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Shopify/themekit/src/env"
+)
+
+func TestTransformAsset(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("transform commands are unix tools")
+	}
+
+	e := &env.Env{Transforms: map[string]string{"*.scss": "tr a-z A-Z", "*.png": "tr a-z A-Z", "*.css": "false"}}
+
+	asset, err := TransformAsset(e, Asset{Key: "assets/app.scss", Value: "body {}"})
+	assert.Nil(t, err)
+	assert.Equal(t, "BODY {}", asset.Value)
+	assert.Equal(t, calculateTextChecksum("BODY {}", false), asset.Checksum)
+
+	asset, err = TransformAsset(e, Asset{Key: "assets/logo.png", Attachment: base64.StdEncoding.EncodeToString([]byte("png"))})
+	assert.Nil(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("PNG")), asset.Attachment)
+
+	asset, err = TransformAsset(e, Asset{Key: "assets/app.js", Value: "var a;", Checksum: "abc"})
+	assert.Nil(t, err)
+	assert.Equal(t, Asset{Key: "assets/app.js", Value: "var a;", Checksum: "abc"}, asset)
+
+	_, err = TransformAsset(e, Asset{Key: "assets/app.css", Value: "body {}"})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "transform 'false' failed for assets/app.css")
+	}
+}
+
+func TestTransformFor(t *testing.T) {
+	transforms := map[string]string{"*.scss": "sass", "snippets/*.liquid": "minify", "*.js": " "}
+	assert.Equal(t, "sass", transformFor("assets/app.scss", transforms))
+	assert.Equal(t, "minify", transformFor("snippets/a.liquid", transforms))
+	assert.Equal(t, "", transformFor("layout/theme.liquid", transforms))
+	assert.Equal(t, "", transformFor("assets/app.js", transforms))
+
+	transforms = map[string]string{"*.liquid": "liquid", "snippets/*.liquid": "snippet", "a*.liquid": "a", "*b.liquid": "b"}
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "snippet", transformFor("snippets/a.liquid", transforms))
+		assert.Equal(t, "a", transformFor("layout/a.liquid", transforms))
+		assert.Equal(t, "b", transformFor("layout/ab.liquid", transforms))
+		assert.Equal(t, "liquid", transformFor("layout/theme.liquid", transforms))
+	}
+}